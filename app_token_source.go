@@ -0,0 +1,154 @@
+package twitchhelix
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// AppTokenSource is a RenewableTokenSource supplying app access tokens
+// obtained with the client credentials grant.
+//
+// The first token is requested when it is first needed. It is cached and
+// replaced shortly before it expires, or when Twitch rejects it. Concurrent
+// callers needing a new token share a single request.
+//
+// An AppTokenSource can be shared by several Clients with SetTokenSource.
+type AppTokenSource struct {
+	// client sends the token requests.
+	client *Client
+
+	// clientSecret is the Twitch application client secret.
+	clientSecret string
+
+	// mu guards token and pending.
+	mu sync.Mutex
+
+	// token is the cached token, nil if none was obtained yet.
+	token *NewToken
+
+	// pending is the token request in flight, nil if none.
+	pending *appTokenRequest
+}
+
+// appTokenRequest is a token request shared by concurrent callers.
+type appTokenRequest struct {
+	// done is closed once token and err are set.
+	done chan struct{}
+
+	// token is the obtained token.
+	token *NewToken
+
+	// err is the error of the request.
+	err error
+}
+
+// NewAppTokenSource returns an AppTokenSource requesting tokens for the
+// application of client, authenticated with clientSecret.
+//
+// Token requests are sent with the HTTPClient and to the OAuth URL of
+// client. The tokens are not saved to the token store of client.
+func NewAppTokenSource(client *Client, clientSecret string) *AppTokenSource {
+	return &AppTokenSource{
+		client:       client,
+		clientSecret: clientSecret,
+	}
+}
+
+// Token returns the cached app access token, requesting a new one if there
+// is none or it is about to expire.
+//
+// If renewing a token that has not expired yet fails, the cached token is
+// returned.
+func (s *AppTokenSource) Token(ctx context.Context) (*NewToken, error) {
+	token, err := s.get(ctx, func(token *NewToken) bool {
+		return !token.expiresWithin(refreshLeeway)
+	})
+	if err != nil && ctx.Err() == nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.token != nil && !s.token.expiresWithin(0) {
+			return s.token.clone(), nil
+		}
+	}
+
+	return token, err
+}
+
+// Renew returns a new app access token if rejected is the cached one, or
+// the cached token if it was already replaced.
+func (s *AppTokenSource) Renew(ctx context.Context, rejected string) (*NewToken, error) {
+	return s.get(ctx, func(token *NewToken) bool {
+		return token.AccessToken != rejected && !token.expiresWithin(refreshLeeway)
+	})
+}
+
+// get returns the cached token if usable reports true for it, or else
+// requests a new one.
+//
+// Callers arriving while a request is in flight wait for it instead of
+// sending their own. If the caller that sent the request gives up, the
+// next waiter sends it again.
+func (s *AppTokenSource) get(ctx context.Context, usable func(*NewToken) bool) (*NewToken, error) {
+	for {
+		s.mu.Lock()
+
+		if s.token != nil && usable(s.token) {
+			token := s.token.clone()
+			s.mu.Unlock()
+
+			return token, nil
+		}
+
+		pending := s.pending
+		if pending == nil {
+			pending = &appTokenRequest{done: make(chan struct{})}
+			s.pending = pending
+			s.mu.Unlock()
+
+			pending.token, pending.err = s.request(ctx)
+
+			s.mu.Lock()
+			if pending.err == nil {
+				s.token = pending.token
+			}
+			s.pending = nil
+			s.mu.Unlock()
+
+			close(pending.done)
+
+			return pending.token.clone(), pending.err
+		}
+
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-pending.done:
+		}
+
+		if errors.Is(pending.err, context.Canceled) || errors.Is(pending.err, context.DeadlineExceeded) {
+			continue
+		}
+
+		if pending.err != nil {
+			return nil, pending.err
+		}
+
+		return pending.token.clone(), nil
+	}
+}
+
+// request obtains a new app access token with the client credentials grant.
+func (s *AppTokenSource) request(ctx context.Context) (*NewToken, error) {
+	token, err := s.client.requestAppToken(ctx, s.clientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	s.client.logger.Debug("obtained app access token")
+
+	return token, nil
+}
@@ -0,0 +1,169 @@
+// Package twitchauth obtains Twitch user access tokens through a browser,
+// using the OAuth authorization code flow.
+package twitchauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	twitchhelix "github.com/v0idzzy/twitch-helix"
+)
+
+// ErrStateMismatch is returned when Twitch redirects back with a state that
+// differs from the one sent, which may indicate a CSRF attempt.
+var ErrStateMismatch = errors.New("twitchauth: state mismatch")
+
+// AuthorizationError is returned when the user denies access or Twitch
+// rejects the authorization request.
+type AuthorizationError struct {
+	// Code is the "error" parameter of the redirect, such as "access_denied".
+	Code string
+
+	// Description is the "error_description" parameter of the redirect.
+	Description string
+}
+
+func (e *AuthorizationError) Error() string {
+	return fmt.Sprintf("twitchauth: authorization failed: %s: %s", e.Code, e.Description)
+}
+
+// Config describes an authorization code flow.
+type Config struct {
+	// ClientSecret is the Twitch application client secret.
+	ClientSecret string
+
+	// RedirectURL is the URL Twitch redirects the user back to, such as
+	// http://localhost:3000/callback.
+	//
+	// It must be registered for the application. A listener is started on
+	// its host and port to receive the redirect.
+	RedirectURL string
+
+	// Scopes are the scopes requested from the user.
+	Scopes []string
+
+	// ForceVerify makes Twitch ask the user to authorize again even if they
+	// already did.
+	ForceVerify bool
+}
+
+// GenerateState returns a random value for the state parameter.
+func GenerateState() (string, error) {
+	b := make([]byte, 32)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("twitchauth: generate state: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Authorize runs the authorization code flow for client.
+//
+// It starts a listener on the host of cfg.RedirectURL and calls open with
+// the URL the user has to visit, for example to open it in a browser or
+// print it. Once Twitch redirects the user back, the state is checked and
+// the code exchanged for a token with client.ExchangeCode, which also saves
+// it to the client's token store.
+//
+// Authorize returns when the flow completes or ctx is done.
+func Authorize(ctx context.Context, client *twitchhelix.Client, cfg Config, open func(authorizeURL string) error) (*twitchhelix.NewToken, error) {
+	redirectURL, err := url.Parse(cfg.RedirectURL)
+	if err != nil {
+		return nil, fmt.Errorf("twitchauth: parse redirect url: %w", err)
+	}
+
+	state, err := GenerateState()
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", redirectURL.Host)
+	if err != nil {
+		return nil, fmt.Errorf("twitchauth: listen: %w", err)
+	}
+
+	codes := make(chan string, 1)
+	errs := make(chan error, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(callbackPath(redirectURL), func(w http.ResponseWriter, r *http.Request) {
+		code, err := readCallback(r.URL.Query(), state)
+		if err != nil {
+			http.Error(w, "Authorization failed, you can close this window.", http.StatusBadRequest)
+
+			select {
+			case errs <- err:
+			default:
+			}
+
+			return
+		}
+
+		_, _ = w.Write([]byte("Authorization complete, you can close this window."))
+
+		select {
+		case codes <- code:
+		default:
+		}
+	})
+
+	server := &http.Server{Handler: mux}
+
+	go server.Serve(listener)
+	defer server.Close()
+
+	err = open(client.AuthorizeURL(cfg.RedirectURL, state, cfg.Scopes, cfg.ForceVerify))
+	if err != nil {
+		return nil, fmt.Errorf("twitchauth: open authorize url: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case err := <-errs:
+		return nil, err
+	case code := <-codes:
+		return client.ExchangeCode(ctx, cfg.ClientSecret, code, cfg.RedirectURL)
+	}
+}
+
+// readCallback returns the code of the redirect query after checking its
+// state against state.
+func readCallback(query url.Values, state string) (string, error) {
+	if code := query.Get("error"); code != "" {
+		return "", &AuthorizationError{
+			Code:        code,
+			Description: query.Get("error_description"),
+		}
+	}
+
+	if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(state)) != 1 {
+		return "", ErrStateMismatch
+	}
+
+	code := query.Get("code")
+	if code == "" {
+		return "", errors.New("twitchauth: redirect carries no code")
+	}
+
+	return code, nil
+}
+
+// callbackPath returns the ServeMux pattern matching exactly the path the
+// redirect arrives at.
+func callbackPath(redirectURL *url.URL) string {
+	if redirectURL.Path == "" || redirectURL.Path == "/" {
+		return "/{$}"
+	}
+
+	return redirectURL.Path
+}
@@ -0,0 +1,182 @@
+package twitchauth_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	twitchhelix "github.com/v0idzzy/twitch-helix"
+	twitchauth "github.com/v0idzzy/twitch-helix/auth"
+)
+
+// fakeTwitch is a fake of the id.twitch.tv endpoints of the authorization
+// code flow.
+type fakeTwitch struct {
+	// server serves the endpoints.
+	server *httptest.Server
+
+	// redirect returns the query Twitch redirects the user back with for
+	// the query of the authorize request.
+	redirect func(authorize url.Values) url.Values
+
+	// token is the response body of the token endpoint.
+	token string
+}
+
+// newFakeTwitch starts a fakeTwitch approving every authorization with the
+// code "code" and exchanging it for the access token "user-token". It is
+// closed when the test finishes.
+func newFakeTwitch(t *testing.T) *fakeTwitch {
+	t.Helper()
+
+	f := &fakeTwitch{
+		redirect: func(authorize url.Values) url.Values {
+			return url.Values{"code": {"code"}, "state": {authorize.Get("state")}}
+		},
+		token: `{"access_token":"user-token","refresh_token":"refresh-token","expires_in":3600,"scope":["chat:read"],"token_type":"bearer"}`,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /oauth2/authorize", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("client_id") != "client-id" || query.Get("response_type") != "code" {
+			http.Error(w, "bad authorize request", http.StatusBadRequest)
+			return
+		}
+
+		http.Redirect(w, r, query.Get("redirect_uri")+"?"+f.redirect(query).Encode(), http.StatusFound)
+	})
+	mux.HandleFunc("POST /oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("grant_type") != "authorization_code" || r.PostFormValue("code") != "code" || r.PostFormValue("client_secret") != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"status":400,"message":"Invalid authorization code"}`)
+
+			return
+		}
+
+		fmt.Fprint(w, f.token)
+	})
+
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+
+	return f
+}
+
+// authorize runs the authorization code flow against f, visiting the
+// authorize URL like a browser would.
+func (f *fakeTwitch) authorize(t *testing.T, client *twitchhelix.Client) (*twitchhelix.NewToken, error) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cfg := twitchauth.Config{
+		ClientSecret: "secret",
+		RedirectURL:  "http://" + freeAddr(t) + "/callback",
+		Scopes:       []string{"chat:read"},
+	}
+
+	return twitchauth.Authorize(ctx, client, cfg, func(authorizeURL string) error {
+		go func() {
+			resp, err := http.Get(authorizeURL)
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+
+		return nil
+	})
+}
+
+// client returns a client using f as its OAuth endpoints.
+func (f *fakeTwitch) client() *twitchhelix.Client {
+	clientID := "client-id"
+
+	return twitchhelix.NewClient(&clientID, nil, nil, twitchhelix.WithAuthURL(f.server.URL+"/oauth2/"))
+}
+
+// freeAddr returns a local address nothing listens on.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	return listener.Addr().String()
+}
+
+func TestAuthorize(t *testing.T) {
+	f := newFakeTwitch(t)
+	client := f.client()
+
+	token, err := f.authorize(t, client)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+
+	if token.AccessToken != "user-token" || token.RefreshToken != "refresh-token" {
+		t.Errorf("token = %+v, want user-token with refresh-token", token)
+	}
+
+	if got := client.GetToken(); got != "user-token" {
+		t.Errorf("GetToken = %q, want the obtained token to be saved", got)
+	}
+}
+
+func TestAuthorizeStateMismatch(t *testing.T) {
+	f := newFakeTwitch(t)
+	f.redirect = func(url.Values) url.Values {
+		return url.Values{"code": {"code"}, "state": {"forged"}}
+	}
+
+	_, err := f.authorize(t, f.client())
+	if !errors.Is(err, twitchauth.ErrStateMismatch) {
+		t.Fatalf("Authorize = %v, want %v", err, twitchauth.ErrStateMismatch)
+	}
+}
+
+func TestAuthorizeAccessDenied(t *testing.T) {
+	f := newFakeTwitch(t)
+	f.redirect = func(authorize url.Values) url.Values {
+		return url.Values{
+			"error":             {"access_denied"},
+			"error_description": {"The user denied you access"},
+			"state":             {authorize.Get("state")},
+		}
+	}
+
+	_, err := f.authorize(t, f.client())
+
+	var authErr *twitchauth.AuthorizationError
+	if !errors.As(err, &authErr) || authErr.Code != "access_denied" {
+		t.Fatalf("Authorize = %v, want access_denied AuthorizationError", err)
+	}
+}
+
+func TestAuthorizeWithoutAccessTokenKeepsStoredToken(t *testing.T) {
+	f := newFakeTwitch(t)
+	f.token = `{"access_token":"","expires_in":3600,"token_type":"bearer"}`
+
+	client := f.client()
+	if err := client.SetToken(&twitchhelix.NewToken{AccessToken: "stored"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.authorize(t, client); err == nil {
+		t.Fatal("Authorize succeeded without an access token")
+	}
+
+	if got := client.GetToken(); got != "stored" {
+		t.Errorf("GetToken = %q, want the stored token to be kept", got)
+	}
+}
@@ -0,0 +1,110 @@
+package twitchhelix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// AuthorizeURL returns the URL a user visits to grant the application
+// access with the OAuth authorization code flow.
+//
+// redirectURI must match a redirect URL registered for the application.
+// state is returned unchanged with the redirect and protects against CSRF.
+// forceVerify makes Twitch ask the user to authorize again even if they
+// already did.
+func (c *Client) AuthorizeURL(redirectURI, state string, scopes []string, forceVerify bool) string {
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", *c.clientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("scope", strings.Join(scopes, " "))
+	query.Set("state", state)
+
+	if forceVerify {
+		query.Set("force_verify", "true")
+	}
+
+	return c.authURL + "authorize?" + query.Encode()
+}
+
+// ExchangeCode exchanges the code Twitch redirected the user back with for
+// a user access token and saves it to the client's token store.
+//
+// redirectURI must be the one passed to AuthorizeURL.
+//
+// If the token was obtained but could not be saved, it is returned together
+// with the error.
+func (c *Client) ExchangeCode(ctx context.Context, clientSecret, code, redirectURI string) (*NewToken, error) {
+	data := url.Values{}
+	data.Add("client_id", *c.clientID)
+	data.Add("client_secret", clientSecret)
+	data.Add("code", code)
+	data.Add("grant_type", "authorization_code")
+	data.Add("redirect_uri", redirectURI)
+
+	var tokenData NewToken
+
+	err := c.postOAuth(ctx, "token", data, &tokenData)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenData.AccessToken == "" {
+		return nil, errNoAccessToken
+	}
+
+	tokenData.Expiry = expiryFromNow(tokenData.ExpiresIn)
+
+	err = c.saveToken(&tokenData)
+	if err != nil {
+		return &tokenData, fmt.Errorf("failed to save token: %w", err)
+	}
+
+	return &tokenData, nil
+}
+
+// postOAuth posts data to the OAuth endpoint at path, relative to the
+// auth URL, and decodes the response into out unless out is nil.
+//
+// Responses with a status other than 2xx are returned as *OAuthError.
+func (c *Client) postOAuth(ctx context.Context, path string, data url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.authURL+path, strings.NewReader(data.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create new http request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.setUserAgent(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newOAuthError(resp.StatusCode, body)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	err = json.Unmarshal(body, out)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return nil
+}
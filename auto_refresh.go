@@ -0,0 +1,124 @@
+package twitchhelix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// refreshLeeway is how long before its expiry a stored token is refreshed.
+const refreshLeeway = 5 * time.Minute
+
+// errNoAccessToken is returned when the token endpoint answered a refresh
+// without an access token.
+var errNoAccessToken = errors.New("token endpoint returned no access token")
+
+// SetRefreshToken enables automatic refresh of a user access token.
+//
+// refreshToken is saved to the token store alongside the current access
+// token. Tokens that are about to expire, and tokens rejected with 401
+// Unauthorized, are replaced by calling Refresh with clientSecret and the
+// stored refresh token. Rotated refresh tokens returned by Twitch replace
+// the stored one.
+//
+// If the token store already holds a refresh token, for example one
+// persisted by a FileTokenStore, use SetClientSecret instead.
+func (c *Client) SetRefreshToken(clientSecret, refreshToken string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	token, err := c.tokenStore.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load token: %w", err)
+	}
+
+	if token == nil {
+		token = &NewToken{}
+	}
+
+	token.RefreshToken = refreshToken
+
+	err = c.tokenStore.Save(token)
+	if err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+
+	c.clientSecret = clientSecret
+
+	return nil
+}
+
+// SetClientSecret enables automatic token refresh with the token store.
+//
+// If the stored token has a refresh token, it is used with Refresh.
+// Otherwise an app access token is requested with RefreshApp.
+func (c *Client) SetClientSecret(clientSecret string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.clientSecret = clientSecret
+}
+
+// canRenew reports whether the client can replace a rejected token.
+func (c *Client) canRenew() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.tokenSource != nil {
+		_, ok := c.tokenSource.(RenewableTokenSource)
+
+		return ok
+	}
+
+	return c.clientSecret != ""
+}
+
+// renewToken replaces the token stale.
+//
+// Concurrent callers are serialized; if another goroutine already replaced
+// stale while this one waited, no further refresh is made.
+func (c *Client) renewToken(ctx context.Context, stale string) error {
+	c.mu.RLock()
+	source := c.tokenSource
+	store := c.tokenStore
+	clientSecret := c.clientSecret
+	c.mu.RUnlock()
+
+	if source != nil {
+		renewable, ok := source.(RenewableTokenSource)
+		if !ok {
+			return errors.New("token source cannot renew tokens")
+		}
+
+		_, err := renewable.Renew(ctx, stale)
+
+		return err
+	}
+
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	current, err := store.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load token: %w", err)
+	}
+
+	if current != nil && current.AccessToken != stale {
+		return nil
+	}
+
+	if current != nil && current.RefreshToken != "" {
+		_, err = c.RefreshContext(ctx, clientSecret, current.RefreshToken)
+	} else {
+		_, err = c.RefreshAppContext(ctx, clientSecret)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	c.logger.Debug("refreshed access token")
+
+	return nil
+}
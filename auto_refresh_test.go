@@ -0,0 +1,245 @@
+package twitchhelix
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// refreshServer serves GetUsers and refresh token requests, accepting only
+// the access tokens it knows.
+type refreshServer struct {
+	// server serves both the Helix and the OAuth endpoints.
+	server *httptest.Server
+
+	// refreshes counts the refresh token requests.
+	refreshes atomic.Int32
+
+	// unavailable makes the token endpoint fail with 503 Service
+	// Unavailable.
+	unavailable atomic.Bool
+
+	// mu guards valid and seen.
+	mu sync.Mutex
+
+	// valid are the access tokens Helix requests are accepted with.
+	valid map[string]bool
+
+	// seen are the access tokens Helix requests were sent with.
+	seen map[string]bool
+}
+
+// newRefreshServer starts a refreshServer accepting the given access
+// tokens. It is closed when the test finishes.
+func newRefreshServer(t *testing.T, valid ...string) *refreshServer {
+	t.Helper()
+
+	s := &refreshServer{
+		valid: make(map[string]bool),
+		seen:  make(map[string]bool),
+	}
+
+	for _, token := range valid {
+		s.valid[token] = true
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /helix/users", s.serveUsers)
+	mux.HandleFunc("POST /oauth2/token", s.serveToken)
+	s.server = httptest.NewServer(mux)
+	t.Cleanup(s.server.Close)
+
+	return s
+}
+
+// accept makes the server accept token.
+func (s *refreshServer) accept(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.valid[token] = true
+}
+
+// client returns a client of the server with the given token.
+func (s *refreshServer) client(token *NewToken) *Client {
+	clientID := "client-id"
+
+	client := NewClient(&clientID, nil, nil, WithBaseURL(s.server.URL+"/helix/"), WithAuthURL(s.server.URL+"/oauth2/"))
+	client.SetClientSecret("secret")
+	_ = client.SetToken(token)
+
+	return client
+}
+
+func (s *refreshServer) serveUsers(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	s.mu.Lock()
+	s.seen[token] = true
+	valid := s.valid[token]
+	s.mu.Unlock()
+
+	if !valid {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":"Unauthorized","status":401,"message":"Invalid OAuth token"}`)
+
+		return
+	}
+
+	fmt.Fprint(w, `{"data":[{"id":"1","login":"user"}]}`)
+}
+
+func (s *refreshServer) serveToken(w http.ResponseWriter, r *http.Request) {
+	if r.PostFormValue("grant_type") != "refresh_token" {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"status":400,"message":"invalid grant type"}`)
+
+		return
+	}
+
+	if s.unavailable.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, `{"status":503,"message":"service unavailable"}`)
+
+		return
+	}
+
+	n := s.refreshes.Add(1)
+
+	// Give concurrent requests time to pile up behind the refresh.
+	time.Sleep(20 * time.Millisecond)
+
+	token := fmt.Sprintf("refreshed-%d", n)
+	s.accept(token)
+
+	fmt.Fprintf(w, `{"access_token":%q,"refresh_token":"refresh-%d","expires_in":3600,"token_type":"bearer"}`, token, n)
+}
+
+func TestClientSharesRefreshOfRejectedToken(t *testing.T) {
+	server := newRefreshServer(t)
+	client := server.client(&NewToken{
+		AccessToken:  "stale",
+		RefreshToken: "refresh",
+		Expiry:       time.Now().Add(time.Hour),
+	})
+
+	var refreshed atomic.Int32
+	client.OnTokenRefreshed(func(*NewToken) {
+		refreshed.Add(1)
+	})
+
+	var wg sync.WaitGroup
+
+	for range 50 {
+		wg.Go(func() {
+			if _, err := client.GetUsers(context.Background(), GetUsersRequest{ID: []string{"1"}}); err != nil {
+				t.Errorf("GetUsers: %v", err)
+			}
+		})
+	}
+
+	wg.Wait()
+
+	if n := server.refreshes.Load(); n != 1 {
+		t.Errorf("server received %d refresh requests, want 1", n)
+	}
+
+	if n := refreshed.Load(); n != 1 {
+		t.Errorf("OnTokenRefreshed called %d times, want 1", n)
+	}
+
+	if token := client.GetToken(); token != "refreshed-1" {
+		t.Errorf("GetToken = %q, want %q", token, "refreshed-1")
+	}
+}
+
+func TestClientRequestsWhileReplacingToken(t *testing.T) {
+	server := newRefreshServer(t, "initial")
+	client := server.client(&NewToken{AccessToken: "initial", RefreshToken: "refresh"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+
+	for range 20 {
+		wg.Go(func() {
+			for range 20 {
+				if _, err := client.GetUsers(ctx, GetUsersRequest{ID: []string{"1"}}); err != nil {
+					t.Errorf("GetUsers: %v", err)
+					return
+				}
+			}
+		})
+	}
+
+	wg.Go(func() {
+		for i := range 20 {
+			token := fmt.Sprintf("set-%d", i)
+			server.accept(token)
+
+			if err := client.SetToken(&NewToken{AccessToken: token, RefreshToken: "refresh"}); err != nil {
+				t.Errorf("SetToken: %v", err)
+			}
+		}
+	})
+
+	wg.Go(func() {
+		for range 5 {
+			if _, err := client.RefreshContext(ctx, "secret", "refresh"); err != nil {
+				t.Errorf("RefreshContext: %v", err)
+			}
+		}
+	})
+
+	wg.Wait()
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	for token := range server.seen {
+		if !server.valid[token] {
+			t.Errorf("request sent with unknown token %q", token)
+		}
+	}
+}
+
+func TestClientUsesExpiringTokenWhenRefreshFails(t *testing.T) {
+	server := newRefreshServer(t, "expiring")
+	server.unavailable.Store(true)
+
+	client := server.client(&NewToken{
+		AccessToken:  "expiring",
+		RefreshToken: "refresh",
+		Expiry:       time.Now().Add(4 * time.Minute),
+	})
+
+	if _, err := client.GetUsers(context.Background(), GetUsersRequest{ID: []string{"1"}}); err != nil {
+		t.Fatalf("GetUsers with a token valid for 4 minutes: %v", err)
+	}
+
+	if token := client.GetToken(); token != "expiring" {
+		t.Errorf("GetToken = %q, want %q", token, "expiring")
+	}
+}
+
+func TestClientFailsWithExpiredTokenWhenRefreshFails(t *testing.T) {
+	server := newRefreshServer(t, "expired")
+	server.unavailable.Store(true)
+
+	client := server.client(&NewToken{
+		AccessToken:  "expired",
+		RefreshToken: "refresh",
+		Expiry:       time.Now().Add(-time.Minute),
+	})
+
+	if _, err := client.GetUsers(context.Background(), GetUsersRequest{ID: []string{"1"}}); err == nil {
+		t.Fatal("GetUsers with an expired token succeeded although refreshing failed")
+	}
+}
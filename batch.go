@@ -0,0 +1,301 @@
+package twitchhelix
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// maxIDsPerRequest is the number of IDs or logins Twitch accepts in a
+// single request.
+const maxIDsPerRequest = 100
+
+// defaultBatchWorkers is the number of concurrent requests used by batched
+// lookups when no worker count is given.
+const defaultBatchWorkers = 4
+
+// batchChunk is one request's worth of values for a single query parameter.
+type batchChunk struct {
+	// param is the query parameter the values are sent as.
+	param string
+
+	// values holds at most maxIDsPerRequest values.
+	values []string
+}
+
+// ChunkError is the failure of one request of a batched lookup.
+type ChunkError struct {
+	// Param is the query parameter the values were sent as, such as "id"
+	// or "login".
+	Param string
+
+	// Values are the values the failed request looked up.
+	Values []string
+
+	// Err is the error the request failed with.
+	Err error
+}
+
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("lookup of %d %s values: %v", len(e.Values), e.Param, e.Err)
+}
+
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError reports the requests of a batched lookup that failed.
+//
+// The results of the other requests are still returned alongside it.
+type BatchError struct {
+	// Failures holds one entry per failed request.
+	Failures []*ChunkError
+
+	// Requests is the total number of requests the lookup made.
+	Requests int
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("%d of %d batch requests failed: %v", len(e.Failures), e.Requests, e.Failures[0])
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, failure := range e.Failures {
+		errs[i] = failure
+	}
+
+	return errs
+}
+
+// UsersBatch is the merged result of GetUsersBatch.
+type UsersBatch struct {
+	// Users contains every user found.
+	Users []User
+
+	// ByID maps user IDs to users.
+	ByID map[string]User
+
+	// ByLogin maps user logins to users.
+	ByLogin map[string]User
+}
+
+// GetUsersBatch looks up any number of users by ID and login.
+//
+// The IDs and logins in req are deduplicated and split into requests of at
+// most 100 values, which are sent by up to workers concurrent goroutines.
+// workers <= 0 uses a default of 4.
+//
+// If some requests fail, the users found by the others are returned
+// together with a *BatchError.
+func (c *Client) GetUsersBatch(ctx context.Context, req GetUsersRequest, workers int) (*UsersBatch, error) {
+	chunks := append(chunkValues("id", req.ID), chunkValues("login", req.Login)...)
+
+	users, err := runBatches(ctx, chunks, workers, func(ctx context.Context, chunk batchChunk) ([]User, error) {
+		var chunkReq GetUsersRequest
+
+		if chunk.param == "id" {
+			chunkReq.ID = chunk.values
+		} else {
+			chunkReq.Login = chunk.values
+		}
+
+		resp, err := c.GetUsers(ctx, chunkReq)
+		if err != nil {
+			return nil, err
+		}
+
+		return resp.Data, nil
+	})
+
+	batch := &UsersBatch{
+		ByID:    make(map[string]User, len(users)),
+		ByLogin: make(map[string]User, len(users)),
+	}
+
+	for _, user := range users {
+		// A user looked up by both ID and login is returned twice.
+		if _, ok := batch.ByID[user.ID]; ok {
+			continue
+		}
+
+		batch.Users = append(batch.Users, user)
+		batch.ByID[user.ID] = user
+		batch.ByLogin[user.Login] = user
+	}
+
+	return batch, err
+}
+
+// StreamsBatch is the merged result of GetStreamsBatch.
+type StreamsBatch struct {
+	// Streams contains every live stream found.
+	Streams []*StreamData
+
+	// ByUserID maps broadcaster IDs to their streams.
+	ByUserID map[string]*StreamData
+
+	// ByUserLogin maps broadcaster logins to their streams.
+	ByUserLogin map[string]*StreamData
+}
+
+// GetStreamsBatch looks up the live streams of any number of broadcasters.
+//
+// The UserID and UserLogin values in req are deduplicated and split into
+// requests of at most 100 values, which are sent by up to workers
+// concurrent goroutines. workers <= 0 uses a default of 4. The other
+// filters of req are sent with every request; First, Before and After are
+// ignored.
+//
+// If some requests fail, the streams found by the others are returned
+// together with a *BatchError.
+func (c *Client) GetStreamsBatch(ctx context.Context, req StreamRequest, workers int) (*StreamsBatch, error) {
+	chunks := append(chunkValues("user_id", derefAll(req.UserID)), chunkValues("user_login", derefAll(req.UserLogin))...)
+
+	streams, err := runBatches(ctx, chunks, workers, func(ctx context.Context, chunk batchChunk) ([]*StreamData, error) {
+		chunkReq := req
+		chunkReq.UserID = nil
+		chunkReq.UserLogin = nil
+		chunkReq.Before = nil
+		chunkReq.After = nil
+
+		// Every broadcaster has at most one live stream, so one page
+		// holds the whole chunk.
+		first := len(chunk.values)
+		chunkReq.First = &first
+
+		values := make([]*string, len(chunk.values))
+		for i := range chunk.values {
+			values[i] = &chunk.values[i]
+		}
+
+		if chunk.param == "user_id" {
+			chunkReq.UserID = values
+		} else {
+			chunkReq.UserLogin = values
+		}
+
+		resp, err := c.GetStreams(ctx, chunkReq)
+		if err != nil {
+			return nil, err
+		}
+
+		return resp.Data, nil
+	})
+
+	batch := &StreamsBatch{
+		ByUserID:    make(map[string]*StreamData, len(streams)),
+		ByUserLogin: make(map[string]*StreamData, len(streams)),
+	}
+
+	for _, stream := range streams {
+		if stream.UserID == nil {
+			continue
+		}
+
+		// A broadcaster looked up by both ID and login is returned twice.
+		if _, ok := batch.ByUserID[*stream.UserID]; ok {
+			continue
+		}
+
+		batch.Streams = append(batch.Streams, stream)
+		batch.ByUserID[*stream.UserID] = stream
+
+		if stream.UserLogin != nil {
+			batch.ByUserLogin[*stream.UserLogin] = stream
+		}
+	}
+
+	return batch, err
+}
+
+// chunkValues deduplicates values and splits them into chunks of at most
+// maxIDsPerRequest values sent as param.
+func chunkValues(param string, values []string) []batchChunk {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+
+	var chunks []batchChunk
+
+	for values := range slices.Chunk(unique, maxIDsPerRequest) {
+		chunks = append(chunks, batchChunk{param: param, values: values})
+	}
+
+	return chunks
+}
+
+// derefAll returns the non-nil values pointed to by values.
+func derefAll(values []*string) []string {
+	out := make([]string, 0, len(values))
+
+	for _, value := range values {
+		if value != nil {
+			out = append(out, *value)
+		}
+	}
+
+	return out
+}
+
+// runBatches calls fetch for every chunk using up to workers goroutines and
+// concatenates the results in chunk order.
+//
+// The returned error is a *BatchError if any chunk failed, nil otherwise.
+func runBatches[T any](ctx context.Context, chunks []batchChunk, workers int, fetch func(ctx context.Context, chunk batchChunk) ([]T, error)) ([]T, error) {
+	if workers <= 0 {
+		workers = defaultBatchWorkers
+	}
+
+	results := make([][]T, len(chunks))
+	errs := make([]error, len(chunks))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for range min(workers, len(chunks)) {
+		wg.Go(func() {
+			for i := range indexes {
+				results[i], errs[i] = fetch(ctx, chunks[i])
+			}
+		})
+	}
+
+	for i := range chunks {
+		indexes <- i
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	var merged []T
+
+	batchErr := &BatchError{Requests: len(chunks)}
+
+	for i, chunk := range chunks {
+		if errs[i] != nil {
+			batchErr.Failures = append(batchErr.Failures, &ChunkError{
+				Param:  chunk.param,
+				Values: chunk.values,
+				Err:    errs[i],
+			})
+
+			continue
+		}
+
+		merged = append(merged, results[i]...)
+	}
+
+	if len(batchErr.Failures) > 0 {
+		return merged, batchErr
+	}
+
+	return merged, nil
+}
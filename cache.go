@@ -0,0 +1,370 @@
+package twitchhelix
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Endpoints whose responses can be cached.
+const (
+	// CacheUsers caches GetUsers lookups per user.
+	CacheUsers = "users"
+
+	// CacheSearchChannels caches SearchChannels results per query.
+	CacheSearchChannels = "search/channels"
+)
+
+// CacheBackend stores cached responses as encoded bytes.
+//
+// Implementations must be safe for concurrent use.
+type CacheBackend interface {
+	// Get returns the value stored under key.
+	// ok is false if there is none or it expired.
+	Get(key string) (value []byte, ok bool)
+
+	// Set stores value under key for ttl.
+	Set(key string, value []byte, ttl time.Duration)
+
+	// Delete removes the value stored under key.
+	Delete(key string)
+}
+
+// LRUCache is an in-memory CacheBackend holding a bounded number of
+// entries. Once full, the least recently used entry is evicted.
+type LRUCache struct {
+	// size is the maximum number of entries.
+	size int
+
+	// mu guards entries and order.
+	mu sync.Mutex
+
+	// entries maps keys to their element in order.
+	entries map[string]*list.Element
+
+	// order lists entries from most to least recently used.
+	order *list.List
+}
+
+// lruEntry is an entry of an LRUCache.
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewLRUCache returns an LRUCache holding at most size entries.
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{
+		size:    max(size, 1),
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Get returns the value stored under key.
+func (l *LRUCache) Get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*lruEntry)
+
+	if time.Now().After(entry.expires) {
+		l.order.Remove(element)
+		delete(l.entries, key)
+
+		return nil, false
+	}
+
+	l.order.MoveToFront(element)
+
+	return entry.value, true
+}
+
+// Set stores value under key for ttl, evicting the least recently used
+// entry if the cache is full.
+func (l *LRUCache) Set(key string, value []byte, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expires := time.Now().Add(ttl)
+
+	if element, ok := l.entries[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value = value
+		entry.expires = expires
+		l.order.MoveToFront(element)
+
+		return
+	}
+
+	l.entries[key] = l.order.PushFront(&lruEntry{key: key, value: value, expires: expires})
+
+	if l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Delete removes the value stored under key.
+func (l *LRUCache) Delete(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, ok := l.entries[key]; ok {
+		l.order.Remove(element)
+		delete(l.entries, key)
+	}
+}
+
+// CacheStats counts the lookups of a cached endpoint.
+type CacheStats struct {
+	// Hits is the number of values served from the cache.
+	Hits uint64
+
+	// Misses is the number of values fetched from Twitch.
+	Misses uint64
+}
+
+// Cache caches responses of read endpoints in a CacheBackend.
+//
+// Users are cached individually and indexed by both ID and login, so a
+// user fetched by ID is also a hit when looked up by login. Their Email is
+// not cached, since it is only returned to tokens with the user:read:email
+// scope, so users served from the cache have an empty Email.
+type Cache struct {
+	// backend stores the cached values.
+	backend CacheBackend
+
+	// mu guards ttls, generations, stats and onLookup.
+	mu sync.RWMutex
+
+	// ttls is how long values of each endpoint are cached.
+	ttls map[string]time.Duration
+
+	// generations is part of every key; bumping it invalidates all values
+	// of an endpoint without touching the backend.
+	generations map[string]uint64
+
+	// stats counts lookups per endpoint.
+	stats map[string]*CacheStats
+
+	// onLookup is called after every lookup.
+	onLookup func(endpoint string, hit bool)
+}
+
+// NewCache returns a Cache storing values in backend.
+// backend can be nil, in which case an LRUCache of 10000 entries is used.
+//
+// Users are cached for an hour and search results for a minute by default;
+// change this with SetTTL.
+func NewCache(backend CacheBackend) *Cache {
+	if backend == nil {
+		backend = NewLRUCache(10000)
+	}
+
+	return &Cache{
+		backend: backend,
+		ttls: map[string]time.Duration{
+			CacheUsers:          time.Hour,
+			CacheSearchChannels: time.Minute,
+		},
+		generations: make(map[string]uint64),
+		stats:       make(map[string]*CacheStats),
+	}
+}
+
+// SetTTL sets how long values of endpoint are cached.
+// A ttl <= 0 disables caching for endpoint.
+func (c *Cache) SetTTL(endpoint string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ttls[endpoint] = ttl
+}
+
+// OnLookup registers fn to be called after every cache lookup with the
+// endpoint and whether the value was served from the cache.
+func (c *Cache) OnLookup(fn func(endpoint string, hit bool)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.onLookup = fn
+}
+
+// Stats returns the lookup counts of endpoint.
+func (c *Cache) Stats(endpoint string) CacheStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	stats, ok := c.stats[endpoint]
+	if !ok {
+		return CacheStats{}
+	}
+
+	return *stats
+}
+
+// InvalidateEndpoint drops every cached value of endpoint.
+func (c *Cache) InvalidateEndpoint(endpoint string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generations[endpoint]++
+}
+
+// InvalidateUser drops the cached user with the given ID.
+func (c *Cache) InvalidateUser(id string) {
+	var user User
+
+	if c.get(CacheUsers, "id:"+id, &user) {
+		c.backend.Delete(c.key(CacheUsers, "login:"+strings.ToLower(user.Login)))
+	}
+
+	c.backend.Delete(c.key(CacheUsers, "id:"+id))
+}
+
+// InvalidateChannel drops cached values describing the channel of
+// broadcasterID. Call it when a channel.update EventSub event arrives.
+//
+// Search results are not indexed by channel, so every cached search result
+// is dropped, whichever channels it lists.
+func (c *Cache) InvalidateChannel(broadcasterID string) {
+	c.InvalidateEndpoint(CacheSearchChannels)
+}
+
+// key returns the backend key of key in the current generation of endpoint.
+func (c *Cache) key(endpoint, key string) string {
+	c.mu.RLock()
+	generation := c.generations[endpoint]
+	c.mu.RUnlock()
+
+	return endpoint + ":" + strconv.FormatUint(generation, 10) + ":" + key
+}
+
+// get decodes the value of key into out and records the lookup.
+func (c *Cache) get(endpoint, key string, out any) bool {
+	data, ok := c.backend.Get(c.key(endpoint, key))
+	hit := ok && json.Unmarshal(data, out) == nil
+
+	c.mu.Lock()
+
+	stats, ok := c.stats[endpoint]
+	if !ok {
+		stats = &CacheStats{}
+		c.stats[endpoint] = stats
+	}
+
+	if hit {
+		stats.Hits++
+	} else {
+		stats.Misses++
+	}
+
+	onLookup := c.onLookup
+	c.mu.Unlock()
+
+	if onLookup != nil {
+		onLookup(endpoint, hit)
+	}
+
+	return hit
+}
+
+// set stores value under key for the TTL of endpoint.
+func (c *Cache) set(endpoint, key string, value any) {
+	c.mu.RLock()
+	ttl := c.ttls[endpoint]
+	c.mu.RUnlock()
+
+	if ttl <= 0 {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+
+	c.backend.Set(c.key(endpoint, key), data, ttl)
+}
+
+// getUsers serves the users of req from the cache and fetches the rest.
+func (c *Cache) getUsers(ctx context.Context, req GetUsersRequest, fetch func(context.Context, GetUsersRequest) (*GetUsersResponse, error)) (*GetUsersResponse, error) {
+	var (
+		resp   GetUsersResponse
+		missed GetUsersRequest
+	)
+
+	for _, id := range req.ID {
+		var user User
+
+		if c.get(CacheUsers, "id:"+id, &user) {
+			resp.Data = append(resp.Data, user)
+		} else {
+			missed.ID = append(missed.ID, id)
+		}
+	}
+
+	for _, login := range req.Login {
+		var (
+			id   string
+			user User
+		)
+
+		if c.get(CacheUsers, "login:"+strings.ToLower(login), &id) && c.get(CacheUsers, "id:"+id, &user) {
+			resp.Data = append(resp.Data, user)
+		} else {
+			missed.Login = append(missed.Login, login)
+		}
+	}
+
+	if len(missed.ID) == 0 && len(missed.Login) == 0 && len(resp.Data) > 0 {
+		return &resp, nil
+	}
+
+	fetched, err := fetch(ctx, missed)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range fetched.Data {
+		user.Email = ""
+
+		c.set(CacheUsers, "id:"+user.ID, user)
+		c.set(CacheUsers, "login:"+strings.ToLower(user.Login), user.ID)
+	}
+
+	resp.Data = append(resp.Data, fetched.Data...)
+
+	return &resp, nil
+}
+
+// SetCache puts cache in front of the client's read endpoints.
+// Pass nil, the default, to disable caching.
+//
+// A Cache can be shared by several clients.
+func (c *Client) SetCache(cache *Cache) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cache = cache
+}
+
+// Cache returns the cache of the client, nil if caching is disabled.
+func (c *Client) Cache() *Cache {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.cache
+}
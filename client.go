@@ -1,13 +1,26 @@
 package twitchhelix
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"sync"
+
+	"go.uber.org/zap"
 )
 
 type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+// Client calls the Twitch Helix API.
+//
+// A Client is safe for concurrent use by multiple goroutines. Tokens are
+// read and replaced atomically: a request uses either the token from before
+// or the one from after a refresh or SetToken call, never a mix of both.
+// Concurrent requests rejected with the same token share a single refresh.
+// Settings changed with the Set methods and Use apply to requests started
+// afterwards.
 type Client struct {
 	// httpClient is used to make HTTP requests.
 	httpClient HTTPClient
@@ -16,11 +29,62 @@ type Client struct {
 	// https://api.twitch.tv/helix/.
 	baseURL string
 
+	// authURL is the base Twitch OAuth URL, typically
+	// https://id.twitch.tv/oauth2/.
+	authURL string
+
+	// userAgent is sent as the User-Agent header if not empty.
+	userAgent string
+
+	// logger logs token refreshes and retries.
+	logger *zap.Logger
+
 	// clientID is the Twitch application client ID.
 	clientID *string
 
-	// token is the OAuth access token associated with the client ID.
-	token *string
+	// tokenStore holds the OAuth access token associated with the client ID
+	// and, for user tokens, its refresh token.
+	tokenStore TokenStore
+
+	// tokenSource supplies tokens instead of tokenStore when set.
+	tokenSource TokenSource
+
+	// sourceToken is the access token last obtained from tokenSource.
+	sourceToken string
+
+	// clientSecret is the Twitch application client secret used to
+	// refresh the token.
+	clientSecret string
+
+	// rateLimiter tracks the rate limit bucket of each token.
+	rateLimiter *RateLimiter
+
+	// retryPolicy controls retries of failed requests. Nil disables retries.
+	retryPolicy *RetryPolicy
+
+	// cache serves read endpoints from cache when set.
+	cache *Cache
+
+	// middlewares wrap every request, outermost first.
+	middlewares []Middleware
+
+	// validation is the result of the last token validation, nil if none.
+	validation *ValidateTokenResponse
+
+	// scopeCheck makes requests fail before being sent when the token is
+	// known to lack the scopes they need.
+	scopeCheck bool
+
+	// onTokenRefreshed is called with every token the client obtains.
+	onTokenRefreshed func(token *NewToken)
+
+	// mu guards tokenStore, tokenSource, sourceToken, clientSecret, rateLimiter,
+	// retryPolicy, cache, middlewares, validation, scopeCheck and
+	// onTokenRefreshed.
+	mu sync.RWMutex
+
+	// refreshMu makes concurrent requests that need a new token share one refresh.
+	refreshMu sync.Mutex
 }
 
 // NewClient initializes and returns a new Twitch API client.
@@ -29,28 +93,209 @@ type Client struct {
 // clientID is the Twitch application client ID.
 // token is the OAuth access token associated with the client ID.
 // httpClient is the HTTP client to use. If nil, http.DefaultClient is used.
+// opts configure the client further, see Option.
 //
 // To generate an OAuth token with the required scopes, use the Twitch CLI.
 // See the Twitch CLI documentation for details.
 // Example:
 // twitch token -u -s 'analytics:read:extensions analytics:read:games bits:read channel:bot channel:manage:ads channel:read:ads channel:manage:broadcast channel:read:charity channel:manage:clips channel:edit:commercial channel:read:editors channel:manage:extensions channel:read:goals channel:read:guest_star channel:manage:guest_star channel:read:hype_train channel:manage:moderators channel:read:polls channel:manage:polls channel:read:predictions channel:manage:predictions channel:manage:raids channel:read:redemptions channel:manage:redemptions channel:manage:schedule channel:read:stream_key channel:read:subscriptions channel:manage:videos channel:read:vips channel:manage:vips channel:moderate clips:edit editor:manage:clips moderation:read moderator:manage:announcements moderator:manage:automod moderator:read:automod_settings moderator:manage:automod_settings moderator:read:banned_users moderator:manage:banned_users moderator:read:blocked_terms moderator:read:chat_messages moderator:manage:blocked_terms moderator:manage:chat_messages moderator:read:chat_settings moderator:manage:chat_settings moderator:read:chatters moderator:read:followers moderator:read:guest_star moderator:manage:guest_star moderator:read:moderators moderator:read:shield_mode moderator:manage:shield_mode moderator:read:shoutouts moderator:manage:shoutouts moderator:read:suspicious_users moderator:read:unban_requests moderator:manage:unban_requests moderator:read:vips moderator:read:warnings moderator:manage:warnings user:bot user:edit user:edit:broadcast user:read:blocked_users user:manage:blocked_users user:read:broadcast user:read:chat user:manage:chat_color user:read:email user:read:emotes user:read:follows user:read:moderated_channels user:read:subscriptions user:read:whispers user:manage:whispers user:write:chat chat:read chat:edit'.
-func NewClient(clientID, token *string, httpClient HTTPClient) *Client {
+func NewClient(clientID, token *string, httpClient HTTPClient, opts ...Option) *Client {
 	if httpClient == nil {
 		httpClient = http.DefaultClient
 	}
 
-	return &Client{
-		httpClient: httpClient,
-		baseURL:    "https://api.twitch.tv/helix/",
-		clientID:   clientID,
-		token:      token,
+	var stored *NewToken
+	if token != nil {
+		stored = &NewToken{AccessToken: *token}
+	}
+
+	c := &Client{
+		httpClient:  httpClient,
+		baseURL:     defaultBaseURL,
+		authURL:     defaultAuthURL,
+		logger:      zap.L(),
+		clientID:    clientID,
+		tokenStore:  NewMemoryTokenStore(stored),
+		rateLimiter: NewRateLimiter(nil),
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
 }
 
+// GetToken returns the current access token, or "" if there is none.
+//
+// With a token source, it is the token last used for a request, and ""
+// before the first request. GetToken never asks the source for a token, so
+// it does not block on a token request.
 func (c *Client) GetToken() string {
-	if c.token == nil {
+	c.mu.RLock()
+	source := c.tokenSource
+	sourceToken := c.sourceToken
+	store := c.tokenStore
+	c.mu.RUnlock()
+
+	if source != nil {
+		return sourceToken
+	}
+
+	token, _ := store.Load()
+	if token == nil {
 		return ""
 	}
 
-	return *c.token
+	return token.AccessToken
+}
+
+// SetTokenStore replaces the store the client reads its token from and
+// saves refreshed tokens to.
+//
+// Use a FileTokenStore to keep rotated refresh tokens across restarts.
+func (c *Client) SetTokenStore(store TokenStore) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tokenStore = store
+}
+
+// SetTokenSource makes the client take its tokens from source instead of
+// its token store. Pass nil to go back to the token store.
+//
+// If source is a RenewableTokenSource it is asked for a new token when a
+// request is rejected with 401 Unauthorized.
+func (c *Client) SetTokenSource(source TokenSource) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tokenSource = source
+	c.sourceToken = ""
+}
+
+// accessToken returns the access token for the next request.
+//
+// Tokens from the token store that are missing or about to expire are
+// refreshed first if the client holds a client secret. If refreshing a
+// token that has not expired yet fails, the token is used anyway.
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	c.mu.RLock()
+	source := c.tokenSource
+	store := c.tokenStore
+	canRefresh := c.clientSecret != ""
+	c.mu.RUnlock()
+
+	if source != nil {
+		token, err := source.Token(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get token from source: %w", err)
+		}
+
+		c.rememberSourceToken(token.AccessToken)
+
+		return token.AccessToken, nil
+	}
+
+	token, err := store.Load()
+	if err != nil {
+		return "", fmt.Errorf("failed to load token: %w", err)
+	}
+
+	if canRefresh && (token == nil || token.AccessToken == "" || token.expiresWithin(refreshLeeway)) {
+		var stale string
+		if token != nil {
+			stale = token.AccessToken
+		}
+
+		err = c.renewToken(ctx, stale)
+
+		switch {
+		case err == nil:
+			token, err = store.Load()
+			if err != nil {
+				return "", fmt.Errorf("failed to load token: %w", err)
+			}
+
+		// A token that has not expired yet is still used, so requests keep
+		// working while Twitch cannot refresh it.
+		case ctx.Err() == nil && stale != "" && !token.expiresWithin(0):
+			c.logger.Warn("failed to refresh expiring token, using current token", zap.Error(err))
+
+		default:
+			return "", fmt.Errorf("failed to refresh expiring token: %w", err)
+		}
+	}
+
+	if token == nil {
+		return "", nil
+	}
+
+	return token.AccessToken, nil
+}
+
+// rememberSourceToken records accessToken as the token last obtained from
+// the token source.
+func (c *Client) rememberSourceToken(accessToken string) {
+	c.mu.RLock()
+	unchanged := c.sourceToken == accessToken
+	c.mu.RUnlock()
+
+	if unchanged {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sourceToken = accessToken
+}
+
+// SetToken replaces the client's token with token, for example one the
+// application loaded from its own storage.
+//
+// The token is saved to the token store; it has no effect while a token
+// source is set. Requests already sent keep the previous token.
+func (c *Client) SetToken(token *NewToken) error {
+	return c.storeToken(token)
+}
+
+// OnTokenRefreshed registers fn to be called with every token the client
+// obtains and saves, whether by refreshing or by an authorization flow, so
+// the application can persist it. Pass nil to remove the callback.
+//
+// fn is called after the token was saved to the token store, also if saving
+// it failed, and must not block.
+func (c *Client) OnTokenRefreshed(fn func(token *NewToken)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.onTokenRefreshed = fn
+}
+
+// saveToken stores token as the client's current token and reports it to
+// the OnTokenRefreshed callback.
+func (c *Client) saveToken(token *NewToken) error {
+	err := c.storeToken(token)
+
+	c.mu.RLock()
+	onTokenRefreshed := c.onTokenRefreshed
+	c.mu.RUnlock()
+
+	if onTokenRefreshed != nil {
+		onTokenRefreshed(token.clone())
+	}
+
+	return err
+}
+
+// storeToken stores token as the client's current token and forgets the
+// validation of the previous one.
+func (c *Client) storeToken(token *NewToken) error {
+	c.mu.Lock()
+	store := c.tokenStore
+	c.validation = nil
+	c.mu.Unlock()
+
+	return store.Save(token)
 }
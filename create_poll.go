@@ -71,13 +71,8 @@ type Choice struct {
 //
 // The broadcaster must be authenticated. Polls must have at least 2 choices and no more than 5.
 // Optionally, viewers can vote using channel points. The poll will run for the specified duration.
+//
+// Requires a user access token with the channel:manage:polls scope.
 func (c *Client) CreatePoll(ctx context.Context, req RequestCreatePoll) (*ResponseCreatePoll, error) {
-	var resp ResponseCreatePoll
-
-	err := c.doRequest(ctx, "POST", "polls", req, &resp)
-	if err != nil {
-		return nil, err
-	}
-
-	return &resp, nil
+	return doDataRequest[ResponseCreatePoll](ctx, c, "POST", "polls", req)
 }
@@ -147,6 +147,8 @@ type GlobalCooldownSetting struct {
 
 // CreateCustomReward creates a new custom channel point reward for a broadcaster.
 // The broadcaster must have channel points enabled.
+//
+// Requires a user access token with the channel:manage:redemptions scope.
 func (c *Client) CreateCustomReward(ctx context.Context, req RequestCustomReward, broadcasterID string) (*ResponseCustomReward, error) {
 	var resp ResponseCustomReward
 
@@ -162,6 +164,8 @@ func (c *Client) CreateCustomReward(ctx context.Context, req RequestCustomReward
 
 // UpdateCustomReward updates the fields of an existing custom channel point reward.
 // Only the fields provided in the request will be updated.
+//
+// Requires a user access token with the channel:manage:redemptions scope.
 func (c *Client) UpdateCustomReward(ctx context.Context, req RequestCustomReward, broadcasterID, rewardID string) (*ResponseCustomReward, error) {
 	var resp ResponseCustomReward
 
@@ -189,6 +193,9 @@ type RequestGetCustomRewards struct {
 
 // GetCustomRewards retrieves a list of custom channel point rewards.
 // If reward IDs are provided, only those rewards will be returned.
+//
+// Requires a user access token with the channel:read:redemptions or
+// channel:manage:redemptions scope.
 func (c *Client) GetCustomRewards(ctx context.Context, req RequestGetCustomRewards) (*ResponseCustomReward, error) {
 	var resp ResponseCustomReward
 
@@ -0,0 +1,127 @@
+package twitchhelix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// deviceCodeGrantType is the grant type of the device authorization grant.
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// defaultDevicePollInterval is how often the token endpoint is polled when
+// Twitch does not specify an interval.
+const defaultDevicePollInterval = 5 * time.Second
+
+// ErrDeviceCodeExpired is returned by PollDeviceToken when the user did not
+// authorize the device before the device code expired.
+var ErrDeviceCodeExpired = errors.New("device code expired")
+
+// DeviceCode is the response of the device authorization endpoint.
+type DeviceCode struct {
+	// DeviceCode identifies the authorization request when polling for the
+	// token.
+	DeviceCode string `json:"device_code"`
+
+	// ExpiresIn is the number of seconds the device code is valid for.
+	ExpiresIn int `json:"expires_in"`
+
+	// Interval is the minimum number of seconds between token polls.
+	Interval int `json:"interval"`
+
+	// UserCode is the code the user enters at VerificationURI.
+	UserCode string `json:"user_code"`
+
+	// VerificationURI is the URL the user visits to authorize the device.
+	VerificationURI string `json:"verification_uri"`
+}
+
+// RequestDeviceCode starts the OAuth device authorization grant.
+//
+// Show the returned UserCode and VerificationURI to the user, then call
+// PollDeviceToken to wait for them to authorize the device.
+func (c *Client) RequestDeviceCode(ctx context.Context, scopes []string) (*DeviceCode, error) {
+	data := url.Values{}
+	data.Add("client_id", *c.clientID)
+	data.Add("scopes", strings.Join(scopes, " "))
+
+	var deviceCode DeviceCode
+
+	err := c.postOAuth(ctx, "device", data, &deviceCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deviceCode, nil
+}
+
+// PollDeviceToken polls the token endpoint until the user authorized the
+// device described by deviceCode, then saves the user access token to the
+// client's token store.
+//
+// scopes must be the ones passed to RequestDeviceCode. Polling honours the
+// interval requested by Twitch and slows down when asked to. It stops with
+// ErrDeviceCodeExpired once the device code expires, with an *OAuthError if
+// the user denied access, or with the context error when ctx is done.
+//
+// If the token was obtained but could not be saved, it is returned together
+// with the error.
+func (c *Client) PollDeviceToken(ctx context.Context, deviceCode *DeviceCode, scopes []string) (*NewToken, error) {
+	interval := time.Duration(deviceCode.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultDevicePollInterval
+	}
+
+	expiry := expiryFromNow(deviceCode.ExpiresIn)
+
+	data := url.Values{}
+	data.Add("client_id", *c.clientID)
+	data.Add("scopes", strings.Join(scopes, " "))
+	data.Add("device_code", deviceCode.DeviceCode)
+	data.Add("grant_type", deviceCodeGrantType)
+
+	for {
+		if !expiry.IsZero() && time.Now().Add(interval).After(expiry) {
+			return nil, ErrDeviceCodeExpired
+		}
+
+		if !sleep(ctx, interval) {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			return nil, context.DeadlineExceeded
+		}
+
+		var tokenData NewToken
+
+		err := c.postOAuth(ctx, "token", data, &tokenData)
+
+		var oauthErr *OAuthError
+
+		switch {
+		case errors.As(err, &oauthErr) && oauthErr.Code == "authorization_pending":
+			continue
+
+		case errors.As(err, &oauthErr) && oauthErr.Code == "slow_down":
+			interval += defaultDevicePollInterval
+
+			continue
+
+		case err != nil:
+			return nil, err
+		}
+
+		tokenData.Expiry = expiryFromNow(tokenData.ExpiresIn)
+
+		err = c.saveToken(&tokenData)
+		if err != nil {
+			return &tokenData, fmt.Errorf("failed to save token: %w", err)
+		}
+
+		return &tokenData, nil
+	}
+}
@@ -4,58 +4,217 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"time"
+
+	"go.uber.org/zap"
 )
 
 // doRequest performs an HTTP request and decodes the response.
 //
+// The request passes through the client's middleware chain before it is
+// sent. The access token is taken from the client's token source or token
+// store. If the request is rejected with 401 Unauthorized and the token can
+// be renewed, it is renewed and the request retried once, unless the token
+// lacks a scope, which a new token would lack as well.
+//
+// With scope checking enabled, requests the token is known not to satisfy
+// fail with a *ScopeError without being sent.
+//
+// Requests wait while the rate limit bucket of the token is empty, and
+// requests rejected with 429 Too Many Requests are retried after the bucket
+// resets.
+//
+// Failed requests are retried according to the client's RetryPolicy.
+//
 // ctx controls cancellation and timeouts.
 // method is the HTTP method (GET, POST, etc.).
 // endpoint is the API path without the base URL.
 // body is encoded as JSON and sent as the request body.
 // out is decoded from the JSON response body.
 func (c *Client) doRequest(ctx context.Context, method string, endpoint string, body any, out any) error {
-	var bodyReader io.Reader
+	_, err := c.handle(ctx, method, endpoint, body, out)
 
-	if body != nil {
-		jsonBody, err := json.Marshal(body)
-		if err != nil {
-			return err
+	return err
+}
+
+// doDataRequest performs an HTTP request like doRequest and returns the
+// first item of the data array Helix wraps the result in.
+//
+// A response without items fails with ErrNoData, unless the request was
+// stopped by DryRunMiddleware, in which case the zero value is returned.
+func doDataRequest[T any](ctx context.Context, c *Client, method string, endpoint string, body any) (*T, error) {
+	var out dataResponse[T]
+
+	resp, err := c.handle(ctx, method, endpoint, body, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(out.Data) == 0 {
+		if resp != nil && resp.StatusCode == http.StatusNoContent {
+			return new(T), nil
 		}
 
-		bodyReader = bytes.NewReader(jsonBody)
+		return nil, ErrNoData
+	}
+
+	return &out.Data[0], nil
+}
+
+// handle passes a request through the client's middleware chain.
+func (c *Client) handle(ctx context.Context, method string, endpoint string, body any, out any) (*Response, error) {
+	req := &Request{
+		Endpoint: endpointName(method, endpoint, body),
+		Method:   method,
+		Path:     endpoint,
+		Body:     body,
+		Out:      out,
+		Header:   make(http.Header),
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+endpoint, bodyReader)
+	return c.handler()(ctx, req)
+}
+
+// execute is the innermost Handler of the middleware chain. It sends req,
+// retrying according to the client's RetryPolicy.
+func (c *Client) execute(ctx context.Context, req *Request) (*Response, error) {
+	err := c.checkScopes(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+*c.token)
-	req.Header.Set("Client-Id", *c.clientID)
+	var jsonBody []byte
 
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
+	if req.Body != nil {
+		jsonBody, err = json.Marshal(req.Body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	policy := c.retry()
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.attempt(ctx, req, jsonBody)
+		if resp != nil {
+			resp.Latency = time.Since(start)
+		}
+
+		if err == nil || !policy.shouldRetry(ctx, req.Method, attempt, err) {
+			return resp, err
+		}
+
+		delay := policy.backoff(attempt)
+
+		c.logger.Debug("retrying request",
+			zap.String("method", req.Method),
+			zap.String("endpoint", req.Path),
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+			zap.Error(err),
+		)
+
+		if !sleep(ctx, delay) {
+			return resp, err
+		}
 	}
+}
 
-	resp, err := c.httpClient.Do(req)
+// attempt sends a request once, renewing the token after a 401 and waiting
+// out 429 responses, and decodes the response into req.Out.
+//
+// The returned Response is nil if no response was received.
+func (c *Client) attempt(ctx context.Context, req *Request, jsonBody []byte) (*Response, error) {
+	token, err := c.accessToken(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
+	limiter := c.limiter()
+	renewed := false
+	rateLimited := 0
 
-		return &TwitchAPIError{
-			StatusCode: resp.StatusCode,
-			Body:       bodyBytes,
+	for {
+		err = limiter.wait(ctx, token)
+		if err != nil {
+			return nil, err
 		}
+
+		httpResp, err := c.send(ctx, req, jsonBody, token)
+		if err != nil {
+			return nil, err
+		}
+
+		limiter.update(token, httpResp.Header)
+
+		switch {
+		case httpResp.StatusCode == http.StatusUnauthorized && !renewed && c.canRenew():
+			apiErr := newTwitchAPIError(httpResp)
+			httpResp.Body.Close()
+
+			if IsMissingScope(apiErr) {
+				return &Response{StatusCode: httpResp.StatusCode, Header: httpResp.Header, Out: req.Out}, apiErr
+			}
+
+			err = c.renewToken(ctx, token)
+			if err != nil {
+				return nil, fmt.Errorf("failed to refresh token after 401: %w", err)
+			}
+
+			token, err = c.accessToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			renewed = true
+
+			continue
+
+		case httpResp.StatusCode == http.StatusTooManyRequests && bucketEmpty(httpResp.Header) && rateLimited < maxRateLimitRetries:
+			httpResp.Body.Close()
+
+			// The next wait blocks until the bucket resets.
+			limiter.exhaust(token, httpResp.Header)
+			rateLimited++
+
+			continue
+		}
+
+		resp := &Response{
+			StatusCode: httpResp.StatusCode,
+			Header:     httpResp.Header,
+			Out:        req.Out,
+		}
+
+		return resp, decodeResponse(httpResp, req.Out)
 	}
+}
+
+// dataResponse is a response carrying its items in a data array.
+type dataResponse[T any] struct {
+	Data []T `json:"data"`
+}
+
+// bucketEmpty reports whether a 429 response was caused by an empty rate
+// limit bucket rather than an endpoint cooldown, such as the one of
+// SendShoutout.
+func bucketEmpty(header http.Header) bool {
+	bucket, ok := parseRateLimit(header)
+
+	return !ok || bucket.Remaining <= 0
+}
+
+// decodeResponse closes resp after decoding its body into out, or returns
+// the error it carries.
+func decodeResponse(resp *http.Response, out any) error {
+	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusUnauthorized {
-		return AuthErr
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newTwitchAPIError(resp)
 	}
 
 	if out != nil {
@@ -64,3 +223,31 @@ func (c *Client) doRequest(ctx context.Context, method string, endpoint string,
 
 	return nil
 }
+
+// send builds and sends a single HTTP request for req authorized with token.
+func (c *Client) send(ctx context.Context, req *Request, body []byte, token string) (*http.Response, error) {
+	var bodyReader io.Reader
+
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, c.baseURL+req.Path, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range req.Header {
+		httpReq.Header[key] = values
+	}
+
+	httpReq.Header.Set("Authorization", "Bearer "+token)
+	httpReq.Header.Set("Client-Id", *c.clientID)
+	c.setUserAgent(httpReq)
+
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	return c.httpClient.Do(httpReq)
+}
@@ -0,0 +1,61 @@
+package twitchhelix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newDataClient returns a client of a server answering every request with
+// body.
+func newDataClient(t *testing.T, body string, opts ...Option) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	clientID := "client-id"
+	token := "token"
+
+	return NewClient(&clientID, &token, nil, append([]Option{WithBaseURL(server.URL + "/")}, opts...)...)
+}
+
+func TestSendMessageReadsData(t *testing.T) {
+	client := newDataClient(t, `{"data":[{"message_id":"abc","is_sent":true}]}`)
+
+	resp, err := client.SendMessage(context.Background(), SendMessageRequest{BroadcasterID: "1", SenderID: "1", Message: "hi"})
+	if err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	if resp.MessageID != "abc" || !resp.IsSent {
+		t.Errorf("response = %+v, want sent message abc", resp)
+	}
+}
+
+func TestSendMessageWithoutData(t *testing.T) {
+	client := newDataClient(t, `{"data":[]}`)
+
+	_, err := client.SendMessage(context.Background(), SendMessageRequest{BroadcasterID: "1", SenderID: "1", Message: "hi"})
+	if !errors.Is(err, ErrNoData) {
+		t.Fatalf("SendMessage = %v, want %v", err, ErrNoData)
+	}
+}
+
+func TestSendMessageDryRun(t *testing.T) {
+	client := newDataClient(t, `{"data":[]}`, WithMiddleware(DryRunMiddleware()))
+
+	resp, err := client.SendMessage(context.Background(), SendMessageRequest{BroadcasterID: "1", SenderID: "1", Message: "hi"})
+	if err != nil {
+		t.Fatalf("SendMessage in a dry run: %v", err)
+	}
+
+	if *resp != (SendMessageResponse{}) {
+		t.Errorf("dry run response = %+v, want the zero value", resp)
+	}
+}
@@ -0,0 +1,129 @@
+package twitchhelix
+
+import (
+	"strings"
+)
+
+// endpoint describes a Helix endpoint called by a Client method.
+type endpoint struct {
+	// Name is the name of the Client method calling the endpoint.
+	Name string
+
+	// Method is the HTTP method of the endpoint.
+	Method string
+
+	// Path is the API path of the endpoint without the base URL and query.
+	Path string
+
+	// SubscriptionType is the EventSub subscription type created by the
+	// method, for methods creating EventSub subscriptions.
+	SubscriptionType string
+
+	// TokenType is the kind of access token the endpoint accepts.
+	TokenType TokenType
+
+	// Scopes are the scopes the token must all have.
+	Scopes []string
+
+	// AnyOf lists scopes of which the token must have at least one.
+	AnyOf []string
+}
+
+// endpoints lists the Helix endpoints called by Client methods.
+//
+// Methods sharing an endpoint are told apart by SubscriptionType; those
+// with a SubscriptionType are listed first.
+var endpoints = []endpoint{
+	{Name: "ChannelChatMessage", Method: "POST", Path: "eventsub/subscriptions", SubscriptionType: "channel.chat.message", TokenType: UserToken, Scopes: []string{"user:read:chat"}},
+	{Name: "EventChannelAdBreakBegin", Method: "POST", Path: "eventsub/subscriptions", SubscriptionType: "channel.ad_break.begin", TokenType: UserToken, Scopes: []string{"channel:read:ads"}},
+	{Name: "EventChannelPointsCustomRewardRedemptionAdd", Method: "POST", Path: "eventsub/subscriptions", SubscriptionType: "channel.channel_points_custom_reward_redemption.add", TokenType: UserToken, AnyOf: []string{"channel:read:redemptions", "channel:manage:redemptions"}},
+	{Name: "EventChannelRaid", Method: "POST", Path: "eventsub/subscriptions", SubscriptionType: "channel.raid", TokenType: UserToken},
+	{Name: "EventChannelSubscriptionGift", Method: "POST", Path: "eventsub/subscriptions", SubscriptionType: "channel.subscription.gift", TokenType: UserToken, Scopes: []string{"channel:read:subscriptions"}},
+	{Name: "EventChannelUpdate", Method: "POST", Path: "eventsub/subscriptions", SubscriptionType: "channel.update", TokenType: UserToken},
+	{Name: "EventStreamOffline", Method: "POST", Path: "eventsub/subscriptions", SubscriptionType: "stream.offline", TokenType: UserToken},
+	{Name: "EventStreamOnline", Method: "POST", Path: "eventsub/subscriptions", SubscriptionType: "stream.online", TokenType: UserToken},
+
+	{Name: "CheckSubscription", Method: "GET", Path: "subscriptions/user", TokenType: UserToken, Scopes: []string{"user:read:subscriptions"}},
+	{Name: "CreateCustomReward", Method: "POST", Path: "channel_points/custom_rewards", TokenType: UserToken, Scopes: []string{"channel:manage:redemptions"}},
+	{Name: "CreateEventSubSubscription", Method: "POST", Path: "eventsub/subscriptions"},
+	{Name: "CreatePoll", Method: "POST", Path: "polls", TokenType: UserToken, Scopes: []string{"channel:manage:polls"}},
+	{Name: "DeleteEventSubSubscription", Method: "DELETE", Path: "eventsub/subscriptions"},
+	{Name: "GetChatters", Method: "GET", Path: "chat/chatters", TokenType: UserToken, Scopes: []string{"moderator:read:chatters"}},
+	{Name: "GetCustomRewards", Method: "GET", Path: "channel_points/custom_rewards", TokenType: UserToken, AnyOf: []string{"channel:read:redemptions", "channel:manage:redemptions"}},
+	{Name: "GetStreams", Method: "GET", Path: "streams"},
+	{Name: "GetSubscriptions", Method: "GET", Path: "subscriptions", TokenType: UserToken, Scopes: []string{"channel:read:subscriptions"}},
+	{Name: "GetUsers", Method: "GET", Path: "users"},
+	{Name: "MakeClip", Method: "POST", Path: "clips", TokenType: UserToken, Scopes: []string{"clips:edit"}},
+	{Name: "ModifyChannelInformation", Method: "PATCH", Path: "channels", TokenType: UserToken, Scopes: []string{"channel:manage:broadcast"}},
+	{Name: "SearchChannels", Method: "GET", Path: "search/channels"},
+	{Name: "SendMessage", Method: "POST", Path: "chat/messages", Scopes: []string{"user:write:chat"}},
+	{Name: "SendShoutout", Method: "POST", Path: "chat/shoutouts", TokenType: UserToken, Scopes: []string{"moderator:manage:shoutouts"}},
+	{Name: "StartRaid", Method: "POST", Path: "raids", TokenType: UserToken, Scopes: []string{"channel:manage:raids"}},
+	{Name: "UpdateCustomReward", Method: "PATCH", Path: "channel_points/custom_rewards", TokenType: UserToken, Scopes: []string{"channel:manage:redemptions"}},
+}
+
+// endpointAliases maps Client methods built on other methods to the method
+// calling the endpoint.
+var endpointAliases = map[string]string{
+	"AllChatters":       "GetChatters",
+	"AllSearchChannels": "SearchChannels",
+	"AllStreams":        "GetStreams",
+	"AllSubscriptions":  "GetSubscriptions",
+	"GetStreamsBatch":   "GetStreams",
+	"GetUsersBatch":     "GetUsers",
+}
+
+// lookupEndpoint returns the endpoint called with method at path, which may
+// include a query string. body is the request body, used to tell apart
+// methods creating EventSub subscriptions.
+func lookupEndpoint(method, path string, body any) (endpoint, bool) {
+	path, _, _ = strings.Cut(path, "?")
+
+	var subscriptionType string
+
+	switch req := body.(type) {
+	case EventRequest:
+		subscriptionType = req.Type
+	case *EventRequest:
+		subscriptionType = req.Type
+	}
+
+	for _, e := range endpoints {
+		if e.Method != method || e.Path != path {
+			continue
+		}
+
+		if e.SubscriptionType == "" || e.SubscriptionType == subscriptionType {
+			return e, true
+		}
+	}
+
+	return endpoint{}, false
+}
+
+// lookupMethod returns the endpoint called by the Client method name.
+func lookupMethod(name string) (endpoint, bool) {
+	if alias, ok := endpointAliases[name]; ok {
+		name = alias
+	}
+
+	for _, e := range endpoints {
+		if e.Name == name {
+			return e, true
+		}
+	}
+
+	return endpoint{}, false
+}
+
+// endpointName returns the name of the endpoint called with method at path,
+// or the method and path without query if the endpoint is unknown.
+func endpointName(method, path string, body any) string {
+	if e, ok := lookupEndpoint(method, path, body); ok {
+		return e.Name
+	}
+
+	path, _, _ = strings.Cut(path, "?")
+
+	return method + " " + path
+}
@@ -1,10 +1,52 @@
 package twitchhelix
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"strings"
 )
 
+// Sentinel errors matched by TwitchAPIError through errors.Is.
+var (
+	// ErrBadRequest matches 400 Bad Request responses.
+	ErrBadRequest = errors.New("bad request")
+
+	// ErrUnauthorized matches 401 Unauthorized responses.
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrForbidden matches 403 Forbidden responses.
+	ErrForbidden = errors.New("forbidden")
+
+	// ErrNotFound matches 404 Not Found responses.
+	ErrNotFound = errors.New("not found")
+
+	// ErrConflict matches 409 Conflict responses.
+	ErrConflict = errors.New("conflict")
+
+	// ErrRateLimited matches 429 Too Many Requests responses.
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// ErrNoData is returned by methods returning a single item, such as
+// CreatePoll, MakeClip and SendMessage, when Twitch responds with success
+// but without the item.
+var ErrNoData = errors.New("response contains no data")
+
+// AuthErr matches 401 Unauthorized responses.
+//
+// Deprecated: Use ErrUnauthorized.
+var AuthErr = ErrUnauthorized
+
+// requestIDHeader is the response header carrying the ID of the request.
+const requestIDHeader = "X-Request-Id"
+
+// missingScopePrefix starts the message of 401 responses caused by a
+// token lacking a scope.
+const missingScopePrefix = "Missing scope"
+
 // TwitchAPIError represents an error response returned by the Twitch Helix API.
 type TwitchAPIError struct {
 	// StatusCode is the HTTP status code returned by the API.
@@ -12,10 +54,123 @@ type TwitchAPIError struct {
 
 	// Body contains the raw response body returned by the API.
 	Body []byte
+
+	// ErrorName is the "error" field of the response, usually the HTTP
+	// status text, such as "Unauthorized".
+	ErrorName string
+
+	// Status is the "status" field of the response.
+	Status int
+
+	// Message is the "message" field of the response, describing what went
+	// wrong, such as "Missing scope: moderator:manage:shoutouts".
+	Message string
+
+	// RateLimit is the rate limit bucket reported with the response.
+	//
+	// It is the zero value if the response had no Ratelimit-* headers.
+	RateLimit RateLimitBucket
+
+	// RequestID is the ID of the request, if the response carried one.
+	RequestID string
+}
+
+// newTwitchAPIError reads the error carried by resp.
+func newTwitchAPIError(resp *http.Response) *TwitchAPIError {
+	body, _ := io.ReadAll(resp.Body)
+
+	apiErr := &TwitchAPIError{
+		StatusCode: resp.StatusCode,
+		Body:       body,
+		RequestID:  resp.Header.Get(requestIDHeader),
+	}
+
+	var envelope struct {
+		Error   string `json:"error"`
+		Status  int    `json:"status"`
+		Message string `json:"message"`
+	}
+
+	// A body that is not the JSON error envelope is kept in Body only.
+	if json.Unmarshal(body, &envelope) == nil {
+		apiErr.ErrorName = envelope.Error
+		apiErr.Status = envelope.Status
+		apiErr.Message = envelope.Message
+	}
+
+	apiErr.RateLimit, _ = parseRateLimit(resp.Header)
+
+	return apiErr
 }
 
 func (e *TwitchAPIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("Twitch API Error(%d): %s", e.StatusCode, e.Message)
+	}
+
 	return fmt.Sprintf("Twitch API Error(%d): %s", e.StatusCode, e.Body)
 }
 
-var AuthErr = errors.New("Auth Error")
+// Is reports whether target is the sentinel error for the status code of e.
+func (e *TwitchAPIError) Is(target error) bool {
+	switch e.StatusCode {
+	case http.StatusBadRequest:
+		return target == ErrBadRequest
+	case http.StatusUnauthorized:
+		return target == ErrUnauthorized
+	case http.StatusForbidden:
+		return target == ErrForbidden
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	case http.StatusConflict:
+		return target == ErrConflict
+	case http.StatusTooManyRequests:
+		return target == ErrRateLimited
+	}
+
+	return false
+}
+
+// IsMissingScope reports whether err is a 401 response caused by the token
+// lacking a required scope, or a *ScopeError.
+func IsMissingScope(err error) bool {
+	_, ok := MissingScope(err)
+
+	return ok
+}
+
+// MissingScope returns the scope Twitch reported as missing in err, or the
+// first scope missing according to a *ScopeError.
+// ok is false if err is not a missing scope error. scope can be empty if
+// Twitch did not name the scope.
+func MissingScope(err error) (scope string, ok bool) {
+	var scopeErr *ScopeError
+	if errors.As(err, &scopeErr) {
+		switch {
+		case len(scopeErr.Missing) > 0:
+			return scopeErr.Missing[0], true
+		case len(scopeErr.AnyOf) > 0:
+			return scopeErr.AnyOf[0], true
+		}
+
+		return "", false
+	}
+
+	var apiErr *TwitchAPIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		return "", false
+	}
+
+	rest, found := strings.CutPrefix(apiErr.Message, missingScopePrefix)
+	if !found {
+		return "", false
+	}
+
+	return strings.TrimSpace(strings.TrimPrefix(rest, ":")), true
+}
+
+// IsRateLimited reports whether err is a 429 Too Many Requests response,
+// such as a shoutout sent while the shoutout cooldown is active.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
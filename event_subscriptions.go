@@ -3,6 +3,8 @@ package twitchhelix
 import (
 	"context"
 	"time"
+
+	"github.com/google/go-querystring/query"
 )
 
 // EventRequest represents a request to create an EventSub subscription.
@@ -39,13 +41,101 @@ type WebsocketTransport struct {
 	DisconnectedAt *time.Time `json:"disconnected_at"`
 }
 
+// EventSubSubscription represents an EventSub subscription.
+type EventSubSubscription struct {
+	// ID is the ID of the subscription.
+	ID string `json:"id"`
+
+	// Status is the status of the subscription, such as "enabled".
+	Status string `json:"status"`
+
+	// Type is the subscription type, such as "stream.online".
+	Type string `json:"type"`
+
+	// Version is the version of the subscription type.
+	Version string `json:"version"`
+
+	// Condition contains the condition of the subscription.
+	Condition any `json:"condition"`
+
+	// CreatedAt is when the subscription was created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// Transport defines how events are delivered.
+	Transport WebsocketTransport `json:"transport"`
+
+	// Cost is how much the subscription counts against the cost limit.
+	Cost int `json:"cost"`
+}
+
+// EventSubSubscriptionResponse represents the response of
+// CreateEventSubSubscription.
+type EventSubSubscriptionResponse struct {
+	// Data contains the created subscription.
+	Data []EventSubSubscription `json:"data"`
+
+	// Total is the number of subscriptions of the client ID.
+	Total int `json:"total"`
+
+	// TotalCost is the summed cost of the subscriptions of the client ID.
+	TotalCost int `json:"total_cost"`
+
+	// MaxTotalCost is the maximum TotalCost allowed.
+	MaxTotalCost int `json:"max_total_cost"`
+}
+
+// CreateEventSubSubscription creates the EventSub subscription described by
+// req, of any subscription type.
+//
+// Requires the access token the subscription type asks for. Subscription
+// types with an Event method have their scopes checked like that method.
+func (c *Client) CreateEventSubSubscription(ctx context.Context, req EventRequest) (*EventSubSubscriptionResponse, error) {
+	var resp EventSubSubscriptionResponse
+
+	err := c.doRequest(ctx, "POST", "eventsub/subscriptions", req, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// RequestDeleteEventSubSubscription represents the parameters to delete an
+// EventSub subscription.
+type RequestDeleteEventSubSubscription struct {
+	// ID is the ID of the subscription to delete.
+	ID string `url:"id"`
+}
+
+// DeleteEventSubSubscription deletes an EventSub subscription.
+//
+// Requires the kind of access token the subscription was created with.
+func (c *Client) DeleteEventSubSubscription(ctx context.Context, req RequestDeleteEventSubSubscription) error {
+	values, err := query.Values(req)
+	if err != nil {
+		return err
+	}
+
+	endpoint := "eventsub/subscriptions?" + values.Encode()
+
+	err = c.doRequest(ctx, "DELETE", endpoint, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // =============================================================
 
+// ChannelChatMessage subscribes to channel.chat.message events.
+//
+// Requires a user access token with the user:read:chat scope.
 func (c *Client) ChannelChatMessage(ctx context.Context, sessionID string, condition ConditionChannelChatMessage) (*any, error) {
 	req := EventRequest{
 		Type:      "channel.chat.message",
 		Version:   "1",
-		Conditoin: c,
+		Conditoin: condition,
 		Transport: WebsocketTransport{
 			Method:    "websocket",
 			SessionID: sessionID,
@@ -76,6 +166,8 @@ type ConditionStreamOnline struct {
 }
 
 // EventStreamOnline subscribes to stream.online events for a broadcaster.
+//
+// Requires a user access token.
 func (c *Client) EventStreamOnline(ctx context.Context, sessionID string, condition ConditionStreamOnline) (*any, error) {
 	req := EventRequest{
 		Type:      "stream.online",
@@ -104,6 +196,8 @@ type ConditionStreamOffline struct {
 }
 
 // EventStreamOffline subscribes to stream.offline events for a broadcaster.
+//
+// Requires a user access token.
 func (c *Client) EventStreamOffline(ctx context.Context, sessionID string, condition ConditionStreamOffline) (*any, error) {
 	req := EventRequest{
 		Type:      "stream.offline",
@@ -132,6 +226,8 @@ type ConditionChannelUpdate struct {
 }
 
 // EventChannelUpdate subscribes to channel.update events for a broadcaster.
+//
+// Requires a user access token.
 func (c *Client) EventChannelUpdate(ctx context.Context, sessionID string, condition ConditionChannelUpdate) (*any, error) {
 	req := EventRequest{
 		Type:      "channel.update",
@@ -165,6 +261,8 @@ type ConditionChannelRaid struct {
 }
 
 // EventChannelRaid subscribes to channel.raid events.
+//
+// Requires a user access token.
 func (c *Client) EventChannelRaid(ctx context.Context, sessionID string, condition ConditionChannelRaid) (*any, error) {
 	req := EventRequest{
 		Type:      "channel.raid",
@@ -193,6 +291,9 @@ type ConditionEventChannelPointsCustomRewardRedemptionAdd struct {
 }
 
 // EventChannelPointsCustomRewardRedemptionAdd subscribes to channel point reward redemption events.
+//
+// Requires a user access token with the channel:read:redemptions or
+// channel:manage:redemptions scope.
 func (c *Client) EventChannelPointsCustomRewardRedemptionAdd(ctx context.Context, sessionID string, condition ConditionEventChannelPointsCustomRewardRedemptionAdd) (*any, error) {
 	req := EventRequest{
 		Type:      "channel.channel_points_custom_reward_redemption.add",
@@ -221,6 +322,8 @@ type ConditionChannelAdBreakBegin struct {
 }
 
 // EventChannelAdBreakBegin subscribes to channel.ad_break.begin events.
+//
+// Requires a user access token with the channel:read:ads scope.
 func (c *Client) EventChannelAdBreakBegin(ctx context.Context, sessionID string, condition ConditionChannelAdBreakBegin) (*any, error) {
 	req := EventRequest{
 		Type:      "channel.ad_break.begin",
@@ -249,6 +352,8 @@ type ConditionEventChannelSubscriptionGift struct {
 }
 
 // EventChannelSubscriptionGift subscribes to channel point reward redemption events.
+//
+// Requires a user access token with the channel:read:subscriptions scope.
 func (c *Client) EventChannelSubscriptionGift(ctx context.Context, sessionID string, condition ConditionEventChannelSubscriptionGift) (*any, error) {
 	req := EventRequest{
 		Type:      "channel.subscription.gift",
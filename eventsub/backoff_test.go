@@ -0,0 +1,50 @@
+package twitcheventsub
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReconnectPolicyBackoff(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy ReconnectPolicy
+		failed int
+		max    time.Duration
+	}{
+		{"first attempt", ReconnectPolicy{BaseDelay: time.Second, MaxDelay: time.Minute}, 0, time.Second},
+		{"doubles", ReconnectPolicy{BaseDelay: time.Second, MaxDelay: time.Minute}, 2, 4 * time.Second},
+		{"capped", ReconnectPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}, 10, 5 * time.Second},
+		{"no cap", ReconnectPolicy{BaseDelay: time.Second}, 3, 8 * time.Second},
+		{"no base", ReconnectPolicy{MaxDelay: time.Second}, 2, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var longest time.Duration
+
+			for range 1000 {
+				delay := tt.policy.backoff(tt.failed)
+				if delay < 0 || delay > tt.max {
+					t.Fatalf("backoff(%d) = %v, want between 0 and %v", tt.failed, delay, tt.max)
+				}
+
+				longest = max(longest, delay)
+			}
+
+			if longest < tt.max/2 {
+				t.Errorf("longest of 1000 delays is %v, want close to %v", longest, tt.max)
+			}
+		})
+	}
+}
+
+func TestReconnectPolicyBackoffWithoutCapDoesNotOverflow(t *testing.T) {
+	policy := ReconnectPolicy{BaseDelay: time.Second}
+
+	for failed := range 200 {
+		if delay := policy.backoff(failed); delay < 0 {
+			t.Fatalf("backoff(%d) = %v, want a delay of at least 0", failed, delay)
+		}
+	}
+}
@@ -1,9 +1,15 @@
 package twitcheventsub
 
 import (
+	"cmp"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
@@ -21,6 +27,27 @@ type SessionConfig struct {
 	mu sync.RWMutex
 
 	logger *zap.Logger
+
+	// url is the URL new sessions are opened on, empty for
+	// TwitchEventSubURL.
+	url string
+
+	// keepaliveTimeout is the keepalive timeout requested for new
+	// sessions, zero for the default of Twitch.
+	keepaliveTimeout time.Duration
+
+	// reconnectPolicy controls the delay before opening a new session
+	// after the connection was lost.
+	reconnectPolicy *ReconnectPolicy
+
+	// state is the current connection state.
+	state ConnectionState
+
+	// onStateChange is called whenever state changes, nil if unset.
+	onStateChange func(ConnectionState, error)
+
+	// subscriptions are created on every new session, nil if unset.
+	subscriptions *SubscriptionSet
 }
 
 // Event represents the information of a received event
@@ -32,6 +59,9 @@ type Event struct {
 	// SubscriptionType represents the event type
 	SubscriptionType string
 
+	// SubscriptionVersion represents the version of the event type
+	SubscriptionVersion string
+
 	// Data represents all data for the event
 	Data []byte
 }
@@ -42,11 +72,10 @@ var TwitchEventSubURL = "wss://eventsub.wss.twitch.tv/ws"
 // NewEventSubWebsocket is a init function for session config
 func NewEventSubWebsocket(eventMessageChan chan<- Event) *SessionConfig {
 	s := &SessionConfig{
-		Events: eventMessageChan,
-		Session: &Session{
-			ReconnectURL: &TwitchEventSubURL,
-		},
-		logger: zap.L(),
+		Events:          eventMessageChan,
+		Session:         &Session{},
+		logger:          zap.L(),
+		reconnectPolicy: DefaultReconnectPolicy(),
 	}
 
 	return s
@@ -56,116 +85,443 @@ func (s *SessionConfig) SetLogger(logger *zap.Logger) {
 	s.logger = logger
 }
 
-// Connect opens a connection to Twitch Event Sub
+// SetURL makes Run open sessions on url instead of TwitchEventSubURL,
+// for example the URL of an eventsubtest.Server.
+func (s *SessionConfig) SetURL(url string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.url = url
+}
+
+// SetSubscriptions attaches set to the session, so its subscriptions are
+// created on every new session and deleted when Run stops.
+func (s *SessionConfig) SetSubscriptions(set *SubscriptionSet) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subscriptions = set
+}
+
+// SessionID returns the ID of the current session, empty before the first
+// welcome message.
+func (s *SessionConfig) SessionID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.Session.ID
+}
+
+// reconnectTimeout bounds the time to connect to the reconnect URL sent by
+// Twitch and be welcomed there. Twitch closes the old connection 30 seconds
+// after asking to reconnect.
+const reconnectTimeout = 30 * time.Second
+
+// errReconnectTimeout is returned when the reconnect URL did not send a
+// welcome message within reconnectTimeout.
+var errReconnectTimeout = errors.New("no welcome message on reconnect URL")
+
+// frame is a message read from a connection.
+type frame struct {
+	// conn is the connection the message was read from.
+	conn *websocket.Conn
+
+	// data is the message.
+	data []byte
+
+	// err is the error reading the message. The connection is unusable
+	// once it is set.
+	err error
+}
+
+// dialResult is the outcome of dialing a reconnect URL.
+type dialResult struct {
+	// conn is the new connection, nil if dialing failed.
+	conn *websocket.Conn
+
+	// err is the error dialing the connection.
+	err error
+}
+
+// closeTimeout bounds the wait for the server to answer the close frame
+// sent when Run stops.
+const closeTimeout = time.Second
+
+// Connect is Run with a context that is never done. It only returns when
+// the ReconnectPolicy gives up.
 func (s *SessionConfig) Connect() error {
-	// Dial the Twitch WebSocket
-	c, _, err := websocket.DefaultDialer.Dial(*s.Session.ReconnectURL, nil)
+	return s.Run(context.Background())
+}
+
+// Run opens a session to Twitch EventSub and sends its messages to Events
+// until ctx is done or the ReconnectPolicy gives up. Events is closed when
+// Run returns, so Run must only be called once.
+//
+// When ctx is done, a normal close frame is sent and the returned error
+// wraps the error and cause of ctx. When the ReconnectPolicy gives up, the returned
+// error wraps the reason the last attempt failed.
+//
+// When Twitch sends a session_reconnect message, the session is moved to
+// the reconnect URL: the old connection is kept, and its events delivered,
+// until the new one is welcomed. The welcome message of the new connection
+// is not sent to Events, as the session and its subscriptions carry over.
+//
+// The connection is considered lost when it is closed or no message arrived
+// on it within the keepalive timeout of the session, see
+// SetKeepaliveTimeout. A new session is then opened after the delay of
+// the ReconnectPolicy and its welcome message is sent to Events. The
+// subscriptions of the lost session have to be created again, which a
+// SubscriptionSet attached with SetSubscriptions does.
+func (s *SessionConfig) Run(ctx context.Context) error {
+	defer close(s.Events)
+
+	attempts := 0
+
+	for {
+		s.setState(StateConnecting, nil)
+
+		welcomed, err := s.serve(ctx)
+		if ctx.Err() != nil {
+			return s.stopped(ctx)
+		}
+
+		if welcomed {
+			attempts = 0
+		}
+
+		s.logger.Warn("eventsub connection lost", zap.Error(err), zap.Int("attempts", attempts))
+		s.setState(StateDisconnected, err)
+
+		s.mu.RLock()
+		policy := s.reconnectPolicy
+		s.mu.RUnlock()
+
+		if policy.MaxAttempts > 0 && attempts >= policy.MaxAttempts {
+			return fmt.Errorf("failed to open session after %d attempts: %w", attempts, err)
+		}
+
+		if !sleep(ctx, policy.backoff(attempts)) {
+			return s.stopped(ctx)
+		}
+
+		attempts++
+	}
+}
+
+// stopped reports that Run stopped because ctx is done and returns the
+// error describing it.
+func (s *SessionConfig) stopped(ctx context.Context) error {
+	err := ctx.Err()
+	if cause := context.Cause(ctx); cause != err {
+		err = fmt.Errorf("%w: %w", err, cause)
+	}
+
+	err = fmt.Errorf("eventsub session stopped: %w", err)
+	s.setState(StateDisconnected, err)
+
+	return err
+}
+
+// dialURL returns the URL new sessions are opened on, with the requested
+// keepalive timeout.
+func (s *SessionConfig) dialURL() (string, error) {
+	s.mu.RLock()
+	rawURL := cmp.Or(s.url, TwitchEventSubURL)
+	keepalive := s.keepaliveTimeout
+	s.mu.RUnlock()
+
+	if keepalive <= 0 {
+		return rawURL, nil
+	}
+
+	u, err := url.Parse(rawURL)
 	if err != nil {
-		return fmt.Errorf("twitch-helix dial websocket: %w", err)
+		return "", fmt.Errorf("failed to parse url: %w", err)
 	}
-	defer c.Close()
 
-	// Read messages from Twitch
-	for {
-		websocketMessageType, message, err := c.ReadMessage()
-		if err != nil {
-			if websocketMessageType == websocket.PingMessage {
-				s.logger.Debug("Websocket PING, but read message has error", zap.Error(err))
-				c.WriteMessage(websocket.PongMessage, nil)
+	query := u.Query()
+	query.Set("keepalive_timeout_seconds", strconv.Itoa(int(keepalive/time.Second)))
+	u.RawQuery = query.Encode()
 
-				continue
-			}
+	return u.String(), nil
+}
 
-			s.logger.Error("Read Error:", zap.Error(err))
+// serve opens a session and delivers its messages until its connection is
+// lost, following reconnect requests. welcomed reports whether the session
+// was welcomed.
+func (s *SessionConfig) serve(ctx context.Context) (welcomed bool, err error) {
+	dialURL, err := s.dialURL()
+	if err != nil {
+		return false, err
+	}
 
-			return nil
+	active, _, err := websocket.DefaultDialer.DialContext(ctx, dialURL, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to dial websocket: %w", err)
+	}
+
+	frames := make(chan frame)
+	done := make(chan struct{})
+
+	var (
+		next        *websocket.Conn
+		dialed      chan dialResult
+		reconnectBy <-chan time.Time
+
+		// workers tracks the goroutines reading and dialing connections.
+		workers sync.WaitGroup
+	)
+
+	s.mu.RLock()
+	deadline := keepaliveDeadline(s.keepaliveTimeout)
+	subscriptions := s.subscriptions
+	s.mu.RUnlock()
+
+	// sessionCtx is done when the session ends, stopping the creation of
+	// subscriptions tracked by subscribing.
+	sessionCtx, cancelSession := context.WithCancel(ctx)
+
+	var subscribing sync.WaitGroup
+
+	// watchdog fires when no message arrived for deadline, which means the
+	// connection is dead even if it was not closed.
+	watchdog := time.NewTimer(deadline)
+
+	defer func() {
+		watchdog.Stop()
+		cancelSession()
+		subscribing.Wait()
+
+		conns := []*websocket.Conn{active}
+		if next != nil {
+			conns = append(conns, next)
 		}
 
-		if websocketMessageType == websocket.PingMessage {
-			s.logger.Debug("Websocket PING")
-			c.WriteMessage(websocket.PongMessage, nil)
+		stopping := ctx.Err() != nil
 
-			continue
+		if subscriptions != nil {
+			if stopping {
+				teardownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), teardownTimeout)
+				subscriptions.teardown(teardownCtx)
+				cancel()
+			} else {
+				subscriptions.reset()
+			}
 		}
 
-		var msg WebsocketMessage
+		if stopping {
+			for _, conn := range conns {
+				sendClose(conn)
+			}
+		}
 
-		err = json.Unmarshal(message, &msg)
-		if err != nil {
-			s.logger.Warn("twitch-helix decode message", zap.Error(err))
+		close(done)
 
-			continue
+		// The readers keep reading until the server answers the close
+		// frame by closing the connection.
+		if stopping {
+			waitTimeout(&workers, closeTimeout)
 		}
 
-		logger := s.logger.With(zap.String("message_id", msg.Metadata.MessageID))
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
 
-		// Handle the different message types
-		switch msg.Metadata.MessageType {
-		case "session_welcome":
-			var parsedMessage WelcomeMessage
+		workers.Wait()
+	}()
 
-			err := json.Unmarshal(message, &parsedMessage)
-			if err != nil {
-				logger.Error("decode session_welcome essage", zap.Error(err))
+	workers.Go(func() { read(active, frames, done) })
 
+	for {
+		select {
+		case <-ctx.Done():
+			return welcomed, ctx.Err()
+
+		case <-reconnectBy:
+			return welcomed, errReconnectTimeout
+
+		case <-watchdog.C:
+			// While reconnecting the old connection may go quiet, and
+			// reconnectBy bounds the wait for the new one.
+			if reconnectBy != nil {
+				watchdog.Reset(deadline)
 				continue
 			}
 
-			s.mu.Lock()
-			s.Session = &parsedMessage.Payload.Session
-			s.mu.Unlock()
+			return welcomed, fmt.Errorf("%w: no message for %s", ErrKeepaliveTimeout, deadline)
 
-			s.Events <- Event{MessageType: parsedMessage.Metadata.MessageType, SubscriptionType: parsedMessage.Metadata.SubscriptionType, Data: message}
+		case result := <-dialed:
+			dialed = nil
 
-		case "session_keepalive":
-			var parsedMessage KeepaliveMessage
+			if result.err != nil {
+				return welcomed, fmt.Errorf("failed to dial reconnect URL: %w", result.err)
+			}
 
-			err := json.Unmarshal(message, &parsedMessage)
-			if err != nil {
-				logger.Error("decode session_eepalive message", zap.Error(err))
+			next = result.conn
+			workers.Go(func() { read(next, frames, done) })
 
-				continue
-			}
+		case f := <-frames:
+			reconnecting := reconnectBy != nil
 
-			s.Events <- Event{MessageType: parsedMessage.Metadata.MessageType, SubscriptionType: parsedMessage.Metadata.SubscriptionType, Data: message}
+			if f.err != nil {
+				// Twitch may close the old connection while the session
+				// moves to the new one; connections replaced by a
+				// reconnect are closed by us.
+				if f.conn != next && (f.conn != active || reconnecting) {
+					continue
+				}
 
-		case "notification":
-			var parsedMessage NotificationMessage
+				return welcomed, fmt.Errorf("failed to read message: %w", f.err)
+			}
 
-			err := json.Unmarshal(message, &parsedMessage)
-			if err != nil {
-				logger.Error("decode notification message", zap.Error(err))
+			watchdog.Reset(deadline)
 
+			var msg WebsocketMessage
+			if err := json.Unmarshal(f.data, &msg); err != nil {
+				s.logger.Warn("twitch-helix decode message", zap.Error(err))
 				continue
 			}
 
-			s.Events <- Event{MessageType: parsedMessage.Metadata.MessageType, SubscriptionType: parsedMessage.Metadata.SubscriptionType, Data: message}
+			logger := s.logger.With(zap.String("message_id", msg.Metadata.MessageID))
 
-		case "reconnect_message":
-			var parsedMessage ReconnectMessage
+			switch msg.Metadata.MessageType {
+			case "session_welcome":
+				var parsedMessage WelcomeMessage
 
-			err := json.Unmarshal(message, &parsedMessage)
-			if err != nil {
-				logger.Error("decode reconnect_message message", zap.Error(err))
+				if err := json.Unmarshal(f.data, &parsedMessage); err != nil {
+					logger.Error("decode session_welcome message", zap.Error(err))
+					continue
+				}
 
-				continue
-			}
+				s.mu.Lock()
+				s.Session = &parsedMessage.Payload.Session
+				s.mu.Unlock()
 
-			s.mu.Lock()
-			logger.Info("reconnect url updated", zap.Stringp("new_url", parsedMessage.Payload.Session.ReconnectURL))
-			s.Session.ReconnectURL = parsedMessage.Payload.Session.ReconnectURL
-			s.mu.Unlock()
+				deadline = keepaliveDeadline(sessionKeepalive(&parsedMessage.Payload.Session))
+				watchdog.Reset(deadline)
 
-			if err := c.Close(); err != nil {
-				logger.Error("failed to close connection afer twitch asked for reconnect", zap.Error(err))
-			}
+				if f.conn == next {
+					closeConn(active)
+					active, next = next, nil
+					reconnectBy = nil
+
+					logger.Info("eventsub session reconnected", zap.String("session_id", parsedMessage.Payload.Session.ID))
+					s.setState(StateConnected, nil)
+
+					continue
+				}
+
+				welcomed = true
+				s.setState(StateConnected, nil)
+
+				if subscriptions != nil {
+					sessionID := parsedMessage.Payload.Session.ID
+					subscribing.Go(func() { subscriptions.run(sessionCtx, sessionID) })
+				}
+
+			case "revocation":
+				var parsedMessage RevocationMessage
+
+				if err := json.Unmarshal(f.data, &parsedMessage); err != nil {
+					logger.Error("decode revocation message", zap.Error(err))
+					continue
+				}
+
+				logger.Warn("eventsub subscription revoked",
+					zap.String("subscription_type", parsedMessage.Payload.Subscription.Type),
+					zap.String("status", parsedMessage.Payload.Subscription.Status))
+
+				if subscriptions != nil {
+					subscriptions.revoke(parsedMessage.Payload.Subscription.ID, parsedMessage.Payload.Subscription.Status)
+				}
 
-			if err := s.Connect(); err != nil {
-				logger.Error("failed to reconnect after twitch asked for reconnect", zap.Error(err))
-				close(s.Events)
+			case "session_reconnect":
+				if f.conn != active || reconnecting {
+					continue
+				}
 
-				return err
+				var parsedMessage ReconnectMessage
+
+				if err := json.Unmarshal(f.data, &parsedMessage); err != nil || parsedMessage.Payload.Session.ReconnectURL == nil {
+					logger.Error("decode session_reconnect message", zap.Error(err))
+					continue
+				}
+
+				reconnectURL := *parsedMessage.Payload.Session.ReconnectURL
+				logger.Info("eventsub reconnect requested", zap.String("reconnect_url", reconnectURL))
+				s.setState(StateReconnecting, nil)
+
+				dialed = make(chan dialResult)
+				reconnectBy = time.After(reconnectTimeout)
+
+				workers.Go(func() { dial(ctx, reconnectURL, dialed, done) })
 			}
+
+			select {
+			case s.Events <- Event{MessageType: msg.Metadata.MessageType, SubscriptionType: msg.Metadata.SubscriptionType, SubscriptionVersion: msg.Metadata.SubscriptionVersion, Data: f.data}:
+			case <-ctx.Done():
+				return welcomed, ctx.Err()
+			}
+		}
+	}
+}
+
+// read sends the messages read from conn to frames until reading fails.
+// Once done is closed, messages are discarded instead.
+func read(conn *websocket.Conn, frames chan<- frame, done <-chan struct{}) {
+	for {
+		_, data, err := conn.ReadMessage()
+
+		select {
+		case frames <- frame{conn: conn, data: data, err: err}:
+		case <-done:
+		}
+
+		if err != nil {
+			return
 		}
 	}
 }
+
+// dial dials url and sends the result to dialed. The connection is closed
+// if done is closed first.
+func dial(ctx context.Context, url string, dialed chan<- dialResult, done <-chan struct{}) {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+
+	select {
+	case dialed <- dialResult{conn: conn, err: err}:
+	case <-done:
+		if conn != nil {
+			_ = conn.Close()
+		}
+	}
+}
+
+// closeConn sends a normal close frame on conn and closes it.
+func closeConn(conn *websocket.Conn) {
+	sendClose(conn)
+	_ = conn.Close()
+}
+
+// sendClose sends a normal close frame on conn.
+func sendClose(conn *websocket.Conn) {
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(closeTimeout))
+}
+
+// waitTimeout waits for wg, giving up after timeout.
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) {
+	waited := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(waited)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-waited:
+	case <-timer.C:
+	}
+}
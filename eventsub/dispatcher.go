@@ -0,0 +1,406 @@
+package twitcheventsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// ErrDispatcherClosed is returned by Dispatch after Close was called.
+var ErrDispatcherClosed = errors.New("dispatcher closed")
+
+// workerQueueSize is the number of events queued for a handler running on
+// workers before Dispatch blocks.
+const workerQueueSize = 64
+
+// Dispatcher decodes notifications and calls the handlers registered for
+// their subscription type and version.
+//
+// Events can come from a SessionConfig, through Run, or from any other
+// transport, such as webhooks, through Dispatch.
+//
+// Dispatcher is safe for concurrent use.
+type Dispatcher struct {
+	// mu guards handlers, fallback, onError and logger.
+	mu sync.RWMutex
+
+	// handlers maps subscription types and versions to their handlers.
+	// An empty version matches every version.
+	handlers map[handlerKey][]*handler
+
+	// fallback is called for notifications without handlers, nil if unset.
+	fallback func(context.Context, Event)
+
+	// onError is called when decoding an event or a handler fails.
+	onError func(context.Context, Event, error)
+
+	// logger logs failing handlers if onError is unset.
+	logger *zap.Logger
+
+	// workers tracks the worker goroutines of every handler.
+	workers sync.WaitGroup
+
+	// queueMu guards closed. Dispatch holds it for reading while queuing
+	// events and Handle while starting workers, so Close cannot close a
+	// queue in use or wait for workers being started.
+	queueMu sync.RWMutex
+
+	// closed reports whether Close was called.
+	closed bool
+}
+
+// handlerKey identifies the handlers of a subscription type and version.
+type handlerKey struct {
+	// subscriptionType is the subscription type, such as "stream.online".
+	subscriptionType string
+
+	// version is the subscription version, empty for every version.
+	version string
+}
+
+// handler is a registered handler.
+type handler struct {
+	// call decodes the event and calls the handler function.
+	call func(context.Context, Event) error
+
+	// queue receives the events for the workers of the handler, nil if
+	// the handler is called by Dispatch.
+	queue chan queuedEvent
+}
+
+// queuedEvent is an event waiting for a worker.
+type queuedEvent struct {
+	// ctx is the context Dispatch was called with.
+	ctx context.Context
+
+	// event is the event to handle.
+	event Event
+}
+
+// handlerConfig is configured by HandlerOptions.
+type handlerConfig struct {
+	// version is the subscription version handled, empty for every one.
+	version string
+
+	// workers is the number of goroutines calling the handler, zero to
+	// call it from Dispatch.
+	workers int
+}
+
+// HandlerOption configures a handler registered with Handle.
+type HandlerOption func(*handlerConfig)
+
+// WithVersion registers the handler for notifications of the given
+// subscription version only. By default a handler registered with Handle
+// gets every version.
+func WithVersion(version string) HandlerOption {
+	return func(c *handlerConfig) {
+		c.version = version
+	}
+}
+
+// WithWorkers calls the handler from n goroutines instead of the one
+// calling Dispatch, so a slow handler does not hold up other events. Events
+// are queued for the workers, and Dispatch blocks while the queue is full.
+//
+// With more than one worker, events may be handled out of order.
+func WithWorkers(n int) HandlerOption {
+	return func(c *handlerConfig) {
+		c.workers = n
+	}
+}
+
+// PanicError is passed to the error handler of a Dispatcher when a handler
+// panicked.
+type PanicError struct {
+	// Value is the value the handler panicked with.
+	Value any
+
+	// Stack is the stack trace of the panic.
+	Stack []byte
+}
+
+// Error returns the panic value.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("eventsub handler panicked: %v", e.Value)
+}
+
+// NewDispatcher returns a Dispatcher without handlers.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		handlers: make(map[handlerKey][]*handler),
+		logger:   zap.L(),
+	}
+}
+
+// SetLogger makes d log failing handlers to logger instead of the global
+// zap logger.
+func (d *Dispatcher) SetLogger(logger *zap.Logger) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.logger = logger
+}
+
+// SetFallback sets fn to be called for notifications no handler is
+// registered for, such as those of unknown subscription types.
+func (d *Dispatcher) SetFallback(fn func(ctx context.Context, event Event)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.fallback = fn
+}
+
+// SetErrorHandler sets fn to be called when an event cannot be decoded for
+// a handler or a handler panics, with a *PanicError. By default such
+// errors are logged.
+func (d *Dispatcher) SetErrorHandler(fn func(ctx context.Context, event Event, err error)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.onError = fn
+}
+
+// Handle registers fn for notifications of subscriptionType, such as
+// "channel.raid". The event of every notification is decoded into a T.
+//
+// Handlers registered WithVersion are called before those for every
+// version, each in the order they were registered. A panicking handler is
+// recovered and reported to the error handler of d.
+//
+// Handlers registered after Close are ignored.
+func Handle[T any](d *Dispatcher, subscriptionType string, fn func(ctx context.Context, event *T), opts ...HandlerOption) {
+	var config handlerConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	h := &handler{
+		call: func(ctx context.Context, event Event) error {
+			decoded, err := decodeEvent[T](event.Data)
+			if err != nil {
+				return fmt.Errorf("failed to decode %s event: %w", subscriptionType, err)
+			}
+
+			fn(ctx, decoded)
+
+			return nil
+		},
+	}
+
+	if config.workers > 0 {
+		h.queue = make(chan queuedEvent, workerQueueSize)
+	}
+
+	// Holding queueMu keeps Close from closing the queues and waiting for
+	// the workers while they are registered and started.
+	d.queueMu.RLock()
+	defer d.queueMu.RUnlock()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		d.logger.Warn("eventsub handler registered after dispatcher closed, ignoring it",
+			zap.String("subscription_type", subscriptionType))
+
+		return
+	}
+
+	key := handlerKey{subscriptionType: subscriptionType, version: config.version}
+	d.handlers[key] = append(d.handlers[key], h)
+
+	for range config.workers {
+		d.workers.Go(func() {
+			for queued := range h.queue {
+				d.call(queued.ctx, h, queued.event)
+			}
+		})
+	}
+}
+
+// Dispatch calls the handlers registered for event, or the fallback if
+// there are none. Messages other than notifications are ignored.
+//
+// Handlers running on workers are handed the event and may still be running
+// when Dispatch returns. An error is only returned if ctx is done while
+// waiting for a worker queue, or if d was closed.
+func (d *Dispatcher) Dispatch(ctx context.Context, event Event) error {
+	if event.MessageType != "notification" {
+		return nil
+	}
+
+	d.queueMu.RLock()
+	closed := d.closed
+	d.queueMu.RUnlock()
+
+	if closed {
+		return ErrDispatcherClosed
+	}
+
+	d.mu.RLock()
+	handlers := d.handlers[handlerKey{subscriptionType: event.SubscriptionType, version: event.SubscriptionVersion}]
+	if event.SubscriptionVersion != "" {
+		handlers = append(handlers[:len(handlers):len(handlers)], d.handlers[handlerKey{subscriptionType: event.SubscriptionType}]...)
+	}
+	fallback := d.fallback
+	d.mu.RUnlock()
+
+	if len(handlers) == 0 {
+		if fallback != nil {
+			fallback(ctx, event)
+		}
+
+		return nil
+	}
+
+	for _, h := range handlers {
+		if h.queue == nil {
+			d.call(ctx, h, event)
+			continue
+		}
+
+		if err := d.enqueue(ctx, h, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// enqueue hands event to the workers of h, waiting while their queue is
+// full.
+func (d *Dispatcher) enqueue(ctx context.Context, h *handler, event Event) error {
+	d.queueMu.RLock()
+	defer d.queueMu.RUnlock()
+
+	if d.closed {
+		return ErrDispatcherClosed
+	}
+
+	select {
+	case h.queue <- queuedEvent{ctx: ctx, event: event}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run dispatches the events received from events until it is closed, as it
+// is when SessionConfig.Run returns, or ctx is done.
+func (d *Dispatcher) Run(ctx context.Context, events <-chan Event) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			if err := d.Dispatch(ctx, event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Close stops the workers of d once they handled the events queued for
+// them. Dispatch fails with ErrDispatcherClosed afterwards, and no handlers
+// may be registered anymore.
+func (d *Dispatcher) Close() {
+	d.queueMu.Lock()
+	if !d.closed {
+		d.closed = true
+
+		d.mu.RLock()
+		for _, handlers := range d.handlers {
+			for _, h := range handlers {
+				if h.queue != nil {
+					close(h.queue)
+				}
+			}
+		}
+		d.mu.RUnlock()
+	}
+	d.queueMu.Unlock()
+
+	d.workers.Wait()
+}
+
+// call calls h with event, reporting errors and panics to the error
+// handler.
+func (d *Dispatcher) call(ctx context.Context, h *handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			d.fail(ctx, event, &PanicError{Value: r, Stack: debug.Stack()})
+		}
+	}()
+
+	if err := h.call(ctx, event); err != nil {
+		d.fail(ctx, event, err)
+	}
+}
+
+// fail reports err about event to the error handler, or logs it.
+func (d *Dispatcher) fail(ctx context.Context, event Event, err error) {
+	d.mu.RLock()
+	onError := d.onError
+	logger := d.logger
+	d.mu.RUnlock()
+
+	if onError != nil {
+		onError(ctx, event, err)
+		return
+	}
+
+	fields := []zap.Field{
+		zap.String("subscription_type", event.SubscriptionType),
+		zap.String("subscription_version", event.SubscriptionVersion),
+		zap.Error(err),
+	}
+
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		fields = append(fields, zap.ByteString("stack", panicErr.Stack))
+	}
+
+	logger.Error("eventsub handler failed", fields...)
+}
+
+// decodeEvent decodes the event of a notification into a T. data is either
+// an EventSub WebSocket message or the body of a webhook notification.
+func decodeEvent[T any](data []byte) (*T, error) {
+	var message struct {
+		Payload struct {
+			Event json.RawMessage `json:"event"`
+		} `json:"payload"`
+
+		Event json.RawMessage `json:"event"`
+	}
+
+	if err := json.Unmarshal(data, &message); err != nil {
+		return nil, err
+	}
+
+	raw := message.Payload.Event
+	if raw == nil {
+		raw = message.Event
+	}
+
+	if raw == nil {
+		return nil, errors.New("notification has no event")
+	}
+
+	var event T
+	if err := json.Unmarshal(raw, &event); err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
@@ -0,0 +1,44 @@
+package twitcheventsub_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	twitcheventsub "github.com/v0idzzy/twitch-helix/eventsub"
+)
+
+func TestHandleAfterCloseIsIgnored(t *testing.T) {
+	d := twitcheventsub.NewDispatcher()
+	d.Close()
+
+	twitcheventsub.Handle(d, "stream.online", func(context.Context, *twitcheventsub.StreamOnlineEvent) {}, twitcheventsub.WithWorkers(2))
+
+	// Close waits for the workers of every handler, so it would block if
+	// the handler registered above had started any.
+	d.Close()
+
+	event := twitcheventsub.Event{MessageType: "notification", SubscriptionType: "stream.online", Data: []byte(`{"payload":{"event":{}}}`)}
+	if err := d.Dispatch(context.Background(), event); !errors.Is(err, twitcheventsub.ErrDispatcherClosed) {
+		t.Errorf("Dispatch = %v, want %v", err, twitcheventsub.ErrDispatcherClosed)
+	}
+}
+
+func TestHandleConcurrentWithClose(t *testing.T) {
+	d := twitcheventsub.NewDispatcher()
+
+	var wg sync.WaitGroup
+
+	for range 20 {
+		wg.Go(func() {
+			twitcheventsub.Handle(d, "stream.online", func(context.Context, *twitcheventsub.StreamOnlineEvent) {}, twitcheventsub.WithWorkers(2))
+		})
+	}
+
+	wg.Go(d.Close)
+	wg.Wait()
+
+	// Workers started by handlers registered before Close stop with it.
+	d.Close()
+}
@@ -0,0 +1,347 @@
+// Package eventsubtest provides a fake of the Twitch EventSub WebSocket
+// server for testing code built on twitcheventsub without talking to Twitch.
+//
+// A Server speaks the EventSub WebSocket protocol: every new connection is
+// greeted with a session_welcome message and kept alive with
+// session_keepalive messages. Tests wait for the sessions clients open and
+// inject notifications, revocations, reconnect requests and disconnects into
+// them:
+//
+//	server := eventsubtest.NewServer()
+//	defer server.Close()
+//
+//	events := make(chan twitcheventsub.Event)
+//	go server.NewSessionConfig(events).Connect()
+//
+//	session, err := server.WaitSession(ctx)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//
+//	err = session.Notify("stream.online", "1", twitcheventsub.StreamOnlineEvent{BroadcasterUserID: "1"})
+package eventsubtest
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	twitcheventsub "github.com/v0idzzy/twitch-helix/eventsub"
+)
+
+// Close codes sent by Twitch when it closes a connection.
+const (
+	CloseInternalServerError      = 4000
+	CloseClientSentInboundTraffic = 4001
+	CloseClientFailedPingPong     = 4002
+	CloseConnectionUnused         = 4003
+	CloseReconnectGraceExpired    = 4004
+	CloseNetworkTimeout           = 4005
+	CloseNetworkError             = 4006
+	CloseInvalidReconnect         = 4007
+)
+
+// Keepalive timeouts accepted by Twitch, in seconds.
+const (
+	defaultKeepaliveTimeout = 10
+	minKeepaliveTimeout     = 10
+	maxKeepaliveTimeout     = 600
+)
+
+// defaultReconnectGrace is how long Twitch keeps the old connection open
+// after asking a client to reconnect.
+const defaultReconnectGrace = 30 * time.Second
+
+// Server is a fake EventSub WebSocket server.
+//
+// Server is safe for concurrent use.
+type Server struct {
+	// server serves the WebSocket endpoint.
+	server *httptest.Server
+
+	// upgrader upgrades requests to WebSocket connections.
+	upgrader websocket.Upgrader
+
+	// wg tracks the goroutines serving connections.
+	wg sync.WaitGroup
+
+	// mu guards the fields below.
+	mu sync.Mutex
+
+	// keepaliveTimeout overrides the keepalive timeout requested by
+	// clients, zero if it does not.
+	keepaliveTimeout int
+
+	// keepaliveInterval is the time without messages after which a
+	// keepalive is sent, zero to derive it from the keepalive timeout and
+	// negative to send none.
+	keepaliveInterval time.Duration
+
+	// reconnectGrace is how long the old connection of a session is kept
+	// open after the client connected to the reconnect URL.
+	reconnectGrace time.Duration
+
+	// sessions maps session IDs to their session.
+	sessions map[string]*Session
+
+	// pending are the new sessions not returned by WaitSession yet.
+	pending []*Session
+
+	// arrived is closed and replaced whenever a session is added to
+	// pending.
+	arrived chan struct{}
+
+	// conns are the open connections.
+	conns map[*conn]struct{}
+
+	// dials is the number of WebSocket connections accepted.
+	dials int
+
+	// closed reports whether Close was called.
+	closed bool
+}
+
+// NewServer starts and returns a Server. The caller should call Close when
+// finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		reconnectGrace: defaultReconnectGrace,
+		sessions:       make(map[string]*Session),
+		arrived:        make(chan struct{}),
+		conns:          make(map[*conn]struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ws", s.serveWebsocket)
+	s.server = httptest.NewServer(mux)
+
+	return s
+}
+
+// Close closes every connection and shuts the server down.
+func (s *Server) Close() {
+	s.mu.Lock()
+	s.closed = true
+	for c := range s.conns {
+		c.drop()
+	}
+	s.mu.Unlock()
+
+	s.server.Close()
+	s.wg.Wait()
+}
+
+// URL returns the WebSocket URL of the server, to be dialed instead of
+// twitcheventsub.TwitchEventSubURL.
+func (s *Server) URL() string {
+	return "ws" + strings.TrimPrefix(s.server.URL, "http") + "/ws"
+}
+
+// NewSessionConfig returns a SessionConfig connecting to the server and
+// sending its events to events.
+func (s *Server) NewSessionConfig(events chan<- twitcheventsub.Event) *twitcheventsub.SessionConfig {
+	config := twitcheventsub.NewEventSubWebsocket(events)
+	config.SetURL(s.URL())
+
+	return config
+}
+
+// SetKeepaliveTimeout sets the keepalive timeout in seconds reported to new
+// sessions, overriding the keepalive_timeout_seconds requested by clients.
+// Unlike Twitch, any positive value is accepted, so tests do not have to
+// wait ten seconds for a keepalive to be missed. Zero restores the default.
+func (s *Server) SetKeepaliveTimeout(seconds int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keepaliveTimeout = seconds
+}
+
+// SetKeepaliveInterval sets the time without messages after which new
+// connections are sent a keepalive. Zero, the default, sends them after
+// half the keepalive timeout of the session, and a negative interval sends
+// none.
+func (s *Server) SetKeepaliveInterval(interval time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keepaliveInterval = interval
+}
+
+// SetReconnectGrace sets how long the old connection of a session is kept
+// open after the client connected to the reconnect URL. It is then closed
+// with CloseReconnectGraceExpired. The default is 30 seconds, like Twitch.
+func (s *Server) SetReconnectGrace(grace time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reconnectGrace = grace
+}
+
+// Dials returns the number of WebSocket connections the server accepted,
+// including connections to reconnect URLs.
+func (s *Server) Dials() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.dials
+}
+
+// Session returns the session with the given ID.
+// ok is false if there is no such session.
+func (s *Server) Session(id string) (session *Session, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok = s.sessions[id]
+
+	return session, ok
+}
+
+// WaitSession returns the next session a client opened by connecting to
+// URL, once its welcome message was sent. Sessions resumed on a reconnect
+// URL are not returned.
+func (s *Server) WaitSession(ctx context.Context) (*Session, error) {
+	for {
+		s.mu.Lock()
+		if len(s.pending) > 0 {
+			session := s.pending[0]
+			s.pending = s.pending[1:]
+			s.mu.Unlock()
+
+			return session, nil
+		}
+		arrived := s.arrived
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to wait for session: %w", ctx.Err())
+		case <-arrived:
+		}
+	}
+}
+
+// serveWebsocket accepts a WebSocket connection, opening a new session or
+// resuming the one of a reconnect URL.
+func (s *Server) serveWebsocket(w http.ResponseWriter, r *http.Request) {
+	timeout, err := keepaliveTimeout(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	c := newConn(ws)
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		c.drop()
+
+		return
+	}
+	s.dials++
+	s.conns[c] = struct{}{}
+	s.wg.Add(1)
+	interval := s.keepaliveInterval
+	grace := s.reconnectGrace
+	timeout = cmp.Or(s.keepaliveTimeout, timeout)
+
+	var session *Session
+	if id := r.URL.Query().Get("reconnect"); id != "" {
+		session = s.sessions[id]
+	} else {
+		session = newSession(s, timeout)
+		s.sessions[session.id] = session
+	}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		s.wg.Done()
+	}()
+
+	var reconnected *reconnect
+
+	ok := session != nil
+	if ok {
+		reconnected, ok = session.resume(c, grace)
+	}
+
+	if !ok {
+		c.close(CloseInvalidReconnect, "Invalid reconnect")
+		c.read()
+
+		return
+	}
+
+	if err := c.send(session.welcome()); err != nil {
+		c.drop()
+		return
+	}
+
+	if reconnected != nil {
+		close(reconnected.done)
+	} else {
+		s.opened(session)
+	}
+
+	if interval == 0 {
+		interval = time.Duration(session.keepaliveTimeout) * time.Second / 2
+	}
+
+	if interval > 0 {
+		go c.keepalive(interval)
+	}
+
+	c.read()
+}
+
+// opened adds session to the sessions returned by WaitSession.
+func (s *Server) opened(session *Session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending = append(s.pending, session)
+	close(s.arrived)
+	s.arrived = make(chan struct{})
+}
+
+// keepaliveTimeout returns the keepalive timeout requested by r, or the
+// default one if r requests none.
+func keepaliveTimeout(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("keepalive_timeout_seconds")
+	if value == "" {
+		return defaultKeepaliveTimeout, nil
+	}
+
+	timeout, err := strconv.Atoi(value)
+	if err != nil || timeout < minKeepaliveTimeout || timeout > maxKeepaliveTimeout {
+		return 0, fmt.Errorf("keepalive_timeout_seconds must be between %d and %d", minKeepaliveTimeout, maxKeepaliveTimeout)
+	}
+
+	return timeout, nil
+}
+
+// newID returns a random ID formatted like a UUID.
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,405 @@
+package eventsubtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	twitcheventsub "github.com/v0idzzy/twitch-helix/eventsub"
+)
+
+// writeTimeout bounds the time spent writing a message to a client.
+const writeTimeout = 5 * time.Second
+
+// Session is an EventSub session opened by a client.
+//
+// Messages are sent on the current connection of the session, which is
+// replaced when the client connects to the reconnect URL sent by Reconnect.
+type Session struct {
+	// server is the Server the session was opened on.
+	server *Server
+
+	// id is the ID of the session.
+	id string
+
+	// keepaliveTimeout is the keepalive timeout of the session in seconds.
+	keepaliveTimeout int
+
+	// connectedAt is when the session was opened.
+	connectedAt time.Time
+
+	// mu guards the fields below.
+	mu sync.Mutex
+
+	// conn is the current connection of the session.
+	conn *conn
+
+	// connections is the number of connections the session was served on.
+	connections int
+
+	// reconnecting is the reconnect the client was asked for, nil if none
+	// is pending.
+	reconnecting *reconnect
+
+	// subscriptions maps subscription types and versions to the ID of the
+	// subscription notifications are sent for.
+	subscriptions map[string]string
+}
+
+// reconnect is a pending move of a session to a new connection.
+type reconnect struct {
+	// done is closed once the new connection was welcomed.
+	done chan struct{}
+}
+
+// newSession returns a session of server with the given keepalive timeout.
+func newSession(server *Server, keepaliveTimeout int) *Session {
+	return &Session{
+		server:           server,
+		id:               newID(),
+		keepaliveTimeout: keepaliveTimeout,
+		connectedAt:      time.Now().UTC(),
+		subscriptions:    make(map[string]string),
+	}
+}
+
+// ID returns the ID of the session.
+func (s *Session) ID() string {
+	return s.id
+}
+
+// KeepaliveTimeout returns the keepalive timeout reported to the client.
+func (s *Session) KeepaliveTimeout() time.Duration {
+	return time.Duration(s.keepaliveTimeout) * time.Second
+}
+
+// Connections returns the number of connections the session was served
+// on, one more for every completed reconnect.
+func (s *Session) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.connections
+}
+
+// Notify sends a notification message for an event of the given
+// subscription type and version, such as "stream.online" and "1". event is
+// encoded as JSON, so it may be an event type of twitcheventsub or a map.
+//
+// Notifications of the same type and version share a subscription ID.
+func (s *Session) Notify(subscriptionType, version string, event any) error {
+	var msg twitcheventsub.NotificationMessage
+	msg.Metadata = metadata("notification", subscriptionType, version)
+	msg.Payload.Subscription = s.subscription(subscriptionType, version, "enabled")
+	msg.Payload.Event = event
+
+	return s.Send(msg)
+}
+
+// Revoke sends a revocation message for the subscription of the given type
+// and version. status is the reason, such as "authorization_revoked",
+// "user_removed" or "version_removed".
+func (s *Session) Revoke(subscriptionType, version, status string) error {
+	var msg twitcheventsub.RevocationMessage
+	msg.Metadata = metadata("revocation", subscriptionType, version)
+	msg.Payload.Subscription = s.subscription(subscriptionType, version, status)
+
+	if err := s.Send(msg); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.subscriptions, subscriptionType+"/"+version)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Keepalive sends a keepalive message right away.
+func (s *Session) Keepalive() error {
+	return s.Send(keepaliveMessage())
+}
+
+// Send sends message encoded as JSON on the current connection. It can be
+// used to send messages the other methods do not, such as malformed ones.
+// A json.RawMessage is sent unchanged.
+func (s *Session) Send(message any) error {
+	if err := s.current().send(message); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return nil
+}
+
+// Reconnect sends a session_reconnect message and waits until the client
+// connected to the reconnect URL and was welcomed there.
+//
+// Like on Twitch, the new connection resumes the session with the same ID
+// and subscriptions. The old connection is closed with
+// CloseReconnectGraceExpired after the reconnect grace time of the Server,
+// unless the client closes it first.
+func (s *Session) Reconnect(ctx context.Context) error {
+	r := &reconnect{done: make(chan struct{})}
+
+	s.mu.Lock()
+	s.reconnecting = r
+	s.mu.Unlock()
+
+	reconnectURL := s.server.URL() + "?reconnect=" + url.QueryEscape(s.id)
+
+	var msg twitcheventsub.ReconnectMessage
+	msg.Metadata = metadata("session_reconnect", "", "")
+	msg.Payload.Session = twitcheventsub.Session{
+		ID:           s.id,
+		Status:       "reconnecting",
+		ReconnectURL: &reconnectURL,
+		ConnectedAt:  s.connectedAt,
+	}
+
+	if err := s.Send(msg); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("failed to wait for reconnect: %w", ctx.Err())
+	case <-r.done:
+		return nil
+	}
+}
+
+// Close sends a close frame with code and text, such as
+// CloseNetworkTimeout, on the current connection and closes it.
+func (s *Session) Close(code int, text string) {
+	s.current().close(code, text)
+}
+
+// Disconnect closes the current connection abruptly, without a close frame,
+// like a network failure would.
+func (s *Session) Disconnect() {
+	s.current().drop()
+}
+
+// WaitClosed waits until the current connection is closed and returns the
+// code of the close frame the client sent, websocket.CloseNoStatusReceived
+// if it had none or websocket.CloseAbnormalClosure if the client sent no
+// close frame.
+func (s *Session) WaitClosed(ctx context.Context) (int, error) {
+	c := s.current()
+
+	select {
+	case <-ctx.Done():
+		return 0, fmt.Errorf("failed to wait for close: %w", ctx.Err())
+	case <-c.done:
+		return c.closeCode, nil
+	}
+}
+
+// current returns the current connection of s.
+func (s *Session) current() *conn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.conn
+}
+
+// resume makes c the current connection of s. If s was already served on
+// another connection, c must be the one the client was asked to reconnect
+// on; the old connection is then closed after grace.
+//
+// The returned reconnect is nil if c is the first connection of s. ok is
+// false if the client was not asked to reconnect.
+func (s *Session) resume(c *conn, grace time.Duration) (r *reconnect, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn != nil {
+		if s.reconnecting == nil {
+			return nil, false
+		}
+
+		old := s.conn
+		time.AfterFunc(grace, func() {
+			old.close(CloseReconnectGraceExpired, "Reconnect grace time expired")
+		})
+
+		r = s.reconnecting
+		s.reconnecting = nil
+	}
+
+	s.conn = c
+	s.connections++
+
+	return r, true
+}
+
+// welcome returns the session_welcome message of s.
+func (s *Session) welcome() twitcheventsub.WelcomeMessage {
+	timeout := s.keepaliveTimeout
+
+	var msg twitcheventsub.WelcomeMessage
+	msg.Metadata = metadata("session_welcome", "", "")
+	msg.Payload.Session = twitcheventsub.Session{
+		ID:                      s.id,
+		Status:                  "connected",
+		KeepaliveTimeoutSeconds: &timeout,
+		ConnectedAt:             s.connectedAt,
+	}
+
+	return msg
+}
+
+// subscription returns the subscription of the given type and version
+// with status.
+func (s *Session) subscription(subscriptionType, version, status string) twitcheventsub.Subscription {
+	key := subscriptionType + "/" + version
+
+	s.mu.Lock()
+	id, ok := s.subscriptions[key]
+	if !ok {
+		id = newID()
+		s.subscriptions[key] = id
+	}
+	s.mu.Unlock()
+
+	return twitcheventsub.Subscription{
+		ID:        id,
+		Status:    status,
+		Type:      subscriptionType,
+		Version:   version,
+		Condition: struct{}{},
+		Transport: twitcheventsub.Transport{
+			Method:    "websocket",
+			SessionID: s.id,
+		},
+		CreatedAt: s.connectedAt,
+	}
+}
+
+// conn is a WebSocket connection of a session.
+type conn struct {
+	// ws is the WebSocket connection.
+	ws *websocket.Conn
+
+	// writeMu serializes writing messages.
+	writeMu sync.Mutex
+
+	// sent receives a value whenever a message was sent.
+	sent chan struct{}
+
+	// done is closed once the connection is closed.
+	done chan struct{}
+
+	// closeCode is the code of the close frame sent by the client. It is
+	// set before done is closed.
+	closeCode int
+}
+
+// newConn returns a conn for ws.
+func newConn(ws *websocket.Conn) *conn {
+	return &conn{
+		ws:        ws,
+		sent:      make(chan struct{}, 1),
+		done:      make(chan struct{}),
+		closeCode: websocket.CloseAbnormalClosure,
+	}
+}
+
+// send sends message encoded as JSON.
+func (c *conn) send(message any) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	_ = c.ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if err := c.ws.WriteMessage(websocket.TextMessage, data); err != nil {
+		return err
+	}
+
+	select {
+	case c.sent <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// close sends a close frame with code and text and closes c.
+func (c *conn) close(code int, text string) {
+	_ = c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(writeTimeout))
+	c.drop()
+}
+
+// drop closes c without a close frame.
+func (c *conn) drop() {
+	_ = c.ws.Close()
+}
+
+// read reads from c until it is closed. Like Twitch, the connection is
+// closed with CloseClientSentInboundTraffic if the client sends a message.
+func (c *conn) read() {
+	defer close(c.done)
+	defer c.drop()
+
+	c.ws.SetCloseHandler(func(code int, _ string) error {
+		c.closeCode = code
+		_ = c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, ""), time.Now().Add(writeTimeout))
+
+		return nil
+	})
+
+	for {
+		if _, _, err := c.ws.ReadMessage(); err != nil {
+			return
+		}
+
+		c.close(CloseClientSentInboundTraffic, "Client sent inbound traffic")
+	}
+}
+
+// keepalive sends a keepalive message whenever no message was sent on c for
+// interval, until c is closed.
+func (c *conn) keepalive(interval time.Duration) {
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-c.sent:
+		case <-timer.C:
+			if err := c.send(keepaliveMessage()); err != nil {
+				return
+			}
+		}
+
+		timer.Reset(interval)
+	}
+}
+
+// keepaliveMessage returns a session_keepalive message.
+func keepaliveMessage() twitcheventsub.KeepaliveMessage {
+	return twitcheventsub.KeepaliveMessage{
+		Metadata: metadata("session_keepalive", "", ""),
+	}
+}
+
+// metadata returns the metadata of a new message of the given type.
+func metadata(messageType, subscriptionType, version string) twitcheventsub.Metadata {
+	return twitcheventsub.Metadata{
+		MessageID:           newID(),
+		MessageType:         messageType,
+		MessageTimestamp:    time.Now().UTC(),
+		SubscriptionType:    subscriptionType,
+		SubscriptionVersion: version,
+	}
+}
@@ -0,0 +1,55 @@
+package twitcheventsub
+
+import "context"
+
+// OnChannelPointsRedemption registers fn for version 1 of
+// channel.channel_points_custom_reward_redemption.add notifications.
+func (d *Dispatcher) OnChannelPointsRedemption(fn func(ctx context.Context, event *ChannelPointsRedemptionEvent), opts ...HandlerOption) {
+	Handle(d, "channel.channel_points_custom_reward_redemption.add", fn, withVersion("1", opts)...)
+}
+
+// OnChannelRaid registers fn for version 1 of channel.raid notifications.
+func (d *Dispatcher) OnChannelRaid(fn func(ctx context.Context, event *ChannelRaidEvent), opts ...HandlerOption) {
+	Handle(d, "channel.raid", fn, withVersion("1", opts)...)
+}
+
+// OnAdBreakBegin registers fn for version 1 of channel.ad_break.begin
+// notifications.
+func (d *Dispatcher) OnAdBreakBegin(fn func(ctx context.Context, event *AdBreakEvent), opts ...HandlerOption) {
+	Handle(d, "channel.ad_break.begin", fn, withVersion("1", opts)...)
+}
+
+// OnStreamOnline registers fn for version 1 of stream.online notifications.
+func (d *Dispatcher) OnStreamOnline(fn func(ctx context.Context, event *StreamOnlineEvent), opts ...HandlerOption) {
+	Handle(d, "stream.online", fn, withVersion("1", opts)...)
+}
+
+// OnStreamOffline registers fn for version 1 of stream.offline
+// notifications.
+func (d *Dispatcher) OnStreamOffline(fn func(ctx context.Context, event *StreamOfflineEvent), opts ...HandlerOption) {
+	Handle(d, "stream.offline", fn, withVersion("1", opts)...)
+}
+
+// OnChannelUpdate registers fn for version 2 of channel.update
+// notifications.
+func (d *Dispatcher) OnChannelUpdate(fn func(ctx context.Context, event *ChannelUpdateEvent), opts ...HandlerOption) {
+	Handle(d, "channel.update", fn, withVersion("2", opts)...)
+}
+
+// OnChannelSubscriptionGift registers fn for version 1 of
+// channel.subscription.gift notifications.
+func (d *Dispatcher) OnChannelSubscriptionGift(fn func(ctx context.Context, event *ChannelSubscriptionGiftEvent), opts ...HandlerOption) {
+	Handle(d, "channel.subscription.gift", fn, withVersion("1", opts)...)
+}
+
+// OnChannelChatMessage registers fn for version 1 of channel.chat.message
+// notifications.
+func (d *Dispatcher) OnChannelChatMessage(fn func(ctx context.Context, event *ChannelChatMessagePayload), opts ...HandlerOption) {
+	Handle(d, "channel.chat.message", fn, withVersion("1", opts)...)
+}
+
+// withVersion returns opts preceded by WithVersion(version), so the
+// version the event type matches is the default.
+func withVersion(version string, opts []HandlerOption) []HandlerOption {
+	return append([]HandlerOption{WithVersion(version)}, opts...)
+}
@@ -0,0 +1,66 @@
+package twitcheventsub
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrKeepaliveTimeout is the reason a connection is considered lost when
+// no message arrived on it within the keepalive timeout of the session.
+var ErrKeepaliveTimeout = errors.New("keepalive timeout exceeded")
+
+// Keepalive timeouts of Twitch.
+const (
+	// defaultKeepaliveTimeout is the keepalive timeout Twitch uses when
+	// none is requested.
+	defaultKeepaliveTimeout = 10 * time.Second
+
+	// minKeepaliveTimeout is the shortest keepalive timeout Twitch
+	// accepts.
+	minKeepaliveTimeout = 10 * time.Second
+
+	// maxKeepaliveTimeout is the longest keepalive timeout Twitch accepts.
+	maxKeepaliveTimeout = 600 * time.Second
+)
+
+// SetKeepaliveTimeout requests Twitch to send a message at least every
+// timeout on new sessions, passed as the keepalive_timeout_seconds
+// parameter. Twitch accepts whole seconds from 10 to 600, so timeout is
+// rounded to a second and clamped to that range. Zero or less, the
+// default, leaves the parameter out so Twitch uses 10 seconds.
+//
+// A connection without messages for longer than the keepalive timeout of
+// its session is considered lost and a new session is opened.
+func (s *SessionConfig) SetKeepaliveTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		timeout = min(max(timeout.Round(time.Second), minKeepaliveTimeout), maxKeepaliveTimeout)
+	} else {
+		timeout = 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keepaliveTimeout = timeout
+}
+
+// keepaliveDeadline returns how long a connection of a session with the
+// given keepalive timeout may go without messages. Twitch sends keepalives
+// about every timeout, so a quarter of it is allowed on top for latency.
+func keepaliveDeadline(timeout time.Duration) time.Duration {
+	if timeout <= 0 {
+		timeout = defaultKeepaliveTimeout
+	}
+
+	return timeout + timeout/4
+}
+
+// sessionKeepalive returns the keepalive timeout of session, zero if it
+// has none.
+func sessionKeepalive(session *Session) time.Duration {
+	if session.KeepaliveTimeoutSeconds == nil {
+		return 0
+	}
+
+	return time.Duration(*session.KeepaliveTimeoutSeconds) * time.Second
+}
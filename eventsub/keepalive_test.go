@@ -0,0 +1,209 @@
+package twitcheventsub_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	twitcheventsub "github.com/v0idzzy/twitch-helix/eventsub"
+	"github.com/v0idzzy/twitch-helix/eventsub/eventsubtest"
+)
+
+// runSession runs a SessionConfig of server until the test finishes,
+// draining its events. It returns a channel receiving the error of changes
+// to StateDisconnected.
+func runSession(t *testing.T, server *eventsubtest.Server, configure func(*twitcheventsub.SessionConfig)) <-chan error {
+	t.Helper()
+
+	events := make(chan twitcheventsub.Event)
+	disconnects := make(chan error, 16)
+
+	config := server.NewSessionConfig(events)
+	config.SetReconnectPolicy(&twitcheventsub.ReconnectPolicy{
+		BaseDelay: 10 * time.Millisecond,
+		MaxDelay:  10 * time.Millisecond,
+	})
+	config.OnStateChange(func(state twitcheventsub.ConnectionState, err error) {
+		if state != twitcheventsub.StateDisconnected {
+			return
+		}
+
+		select {
+		case disconnects <- err:
+		default:
+		}
+	})
+
+	if configure != nil {
+		configure(config)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+
+	go func() {
+		for range events {
+		}
+	}()
+
+	go func() {
+		stopped <- config.Run(ctx)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+
+		if err := <-stopped; !errors.Is(err, context.Canceled) {
+			t.Errorf("Run = %v, want %v", err, context.Canceled)
+		}
+	})
+
+	return disconnects
+}
+
+func TestKeepaliveTimeoutOpensNewSession(t *testing.T) {
+	server := eventsubtest.NewServer()
+	defer server.Close()
+
+	// Sessions expect a message every second but never get keepalives.
+	server.SetKeepaliveTimeout(1)
+	server.SetKeepaliveInterval(-1)
+
+	disconnects := runSession(t, server, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	first, err := server.WaitSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	welcomed := time.Now()
+
+	select {
+	case <-ctx.Done():
+		t.Fatal("connection without keepalives was not considered lost")
+	case err := <-disconnects:
+		if !errors.Is(err, twitcheventsub.ErrKeepaliveTimeout) {
+			t.Fatalf("disconnected with %v, want %v", err, twitcheventsub.ErrKeepaliveTimeout)
+		}
+	}
+
+	// The deadline is the keepalive timeout plus a quarter of it.
+	if elapsed := time.Since(welcomed); elapsed < time.Second {
+		t.Errorf("keepalive timeout after %v, want at least 1s", elapsed)
+	}
+
+	second, err := server.WaitSession(ctx)
+	if err != nil {
+		t.Fatalf("no new session after keepalive timeout: %v", err)
+	}
+
+	if second.ID() == first.ID() {
+		t.Errorf("new session has the ID %q of the lost one", second.ID())
+	}
+}
+
+func TestKeepalivesKeepSessionOpen(t *testing.T) {
+	server := eventsubtest.NewServer()
+	defer server.Close()
+
+	server.SetKeepaliveTimeout(1)
+
+	disconnects := runSession(t, server, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := server.WaitSession(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-disconnects:
+		t.Fatalf("session with keepalives disconnected: %v", err)
+	case <-time.After(3 * time.Second):
+	}
+
+	if dials := server.Dials(); dials != 1 {
+		t.Errorf("server accepted %d connections, want 1", dials)
+	}
+}
+
+func TestSetKeepaliveTimeoutRequestsTimeout(t *testing.T) {
+	server := eventsubtest.NewServer()
+	defer server.Close()
+
+	runSession(t, server, func(config *twitcheventsub.SessionConfig) {
+		config.SetKeepaliveTimeout(30 * time.Second)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	session, err := server.WaitSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if timeout := session.KeepaliveTimeout(); timeout != 30*time.Second {
+		t.Errorf("session keepalive timeout = %v, want 30s requested by keepalive_timeout_seconds", timeout)
+	}
+}
+
+func TestDefaultKeepaliveTimeout(t *testing.T) {
+	server := eventsubtest.NewServer()
+	defer server.Close()
+
+	runSession(t, server, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	session, err := server.WaitSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if timeout := session.KeepaliveTimeout(); timeout != 10*time.Second {
+		t.Errorf("session keepalive timeout = %v, want the default of 10s", timeout)
+	}
+}
+
+func TestSetKeepaliveTimeoutClampsTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		want    time.Duration
+	}{
+		{"sub-second", 500 * time.Millisecond, 10 * time.Second},
+		{"below minimum", 3 * time.Second, 10 * time.Second},
+		{"fraction of a second", 30*time.Second + 600*time.Millisecond, 31 * time.Second},
+		{"above maximum", time.Hour, 600 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := eventsubtest.NewServer()
+			defer server.Close()
+
+			runSession(t, server, func(config *twitcheventsub.SessionConfig) {
+				config.SetKeepaliveTimeout(tt.timeout)
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			session, err := server.WaitSession(ctx)
+			if err != nil {
+				t.Fatalf("no session with keepalive timeout %v: %v", tt.timeout, err)
+			}
+
+			if timeout := session.KeepaliveTimeout(); timeout != tt.want {
+				t.Errorf("session keepalive timeout = %v, want %v", timeout, tt.want)
+			}
+		})
+	}
+}
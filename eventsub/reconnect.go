@@ -0,0 +1,155 @@
+package twitcheventsub
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// ConnectionState is the state of the connection of a SessionConfig.
+type ConnectionState int
+
+const (
+	// StateConnecting means a new session is being opened.
+	StateConnecting ConnectionState = iota
+
+	// StateConnected means the session was welcomed and receives events.
+	StateConnected
+
+	// StateReconnecting means Twitch asked to move the session to a new
+	// connection and the handshake is in progress. Events keep arriving on
+	// the old connection until the new one is welcomed.
+	StateReconnecting
+
+	// StateDisconnected means the connection was lost. A new session is
+	// opened after the delay of the ReconnectPolicy, unless Run stops.
+	StateDisconnected
+)
+
+// String returns the name of the state.
+func (s ConnectionState) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateDisconnected:
+		return "disconnected"
+	default:
+		return "unknown"
+	}
+}
+
+// ReconnectPolicy controls how long Run waits before opening a new session
+// after the connection was lost, and when it gives up.
+type ReconnectPolicy struct {
+	// MaxAttempts is the number of attempts to open a new session after a
+	// session was lost or could not be opened, before Run gives up. Zero
+	// means no limit.
+	MaxAttempts int
+
+	// BaseDelay is the upper bound of the delay before the first attempt
+	// after a session was lost. It doubles with every failed attempt.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between two attempts. Zero means no cap.
+	MaxDelay time.Duration
+}
+
+// DefaultReconnectPolicy returns a policy never giving up, waiting up to 1
+// second before the first attempt and up to 2 minutes between later ones.
+func DefaultReconnectPolicy() *ReconnectPolicy {
+	return &ReconnectPolicy{
+		BaseDelay: time.Second,
+		MaxDelay:  2 * time.Minute,
+	}
+}
+
+// SetReconnectPolicy sets the reconnect policy of the session.
+// A nil policy restores DefaultReconnectPolicy.
+func (s *SessionConfig) SetReconnectPolicy(policy *ReconnectPolicy) {
+	if policy == nil {
+		policy = DefaultReconnectPolicy()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reconnectPolicy = policy
+}
+
+// OnStateChange sets fn to be called whenever the connection state changes.
+// err is the reason of a change to StateDisconnected and nil otherwise.
+//
+// fn is called from the goroutine running Run, so it must not block.
+func (s *SessionConfig) OnStateChange(fn func(state ConnectionState, err error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.onStateChange = fn
+}
+
+// State returns the current connection state.
+func (s *SessionConfig) State() ConnectionState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.state
+}
+
+// setState changes the connection state to state, reporting it to the
+// OnStateChange callback.
+func (s *SessionConfig) setState(state ConnectionState, err error) {
+	s.mu.Lock()
+	s.state = state
+	fn := s.onStateChange
+	s.mu.Unlock()
+
+	if fn != nil {
+		fn(state, err)
+	}
+}
+
+// backoff returns a random delay before the attempt following the given
+// number of failed ones. A MaxDelay of zero or less does not cap the
+// delay, and a BaseDelay of zero or less uses MaxDelay as the upper bound.
+func (p *ReconnectPolicy) backoff(failed int) time.Duration {
+	delay := p.BaseDelay
+	if delay <= 0 {
+		delay = p.MaxDelay
+	}
+
+	for range failed {
+		if (p.MaxDelay > 0 && delay >= p.MaxDelay) || delay > math.MaxInt64/2 {
+			break
+		}
+
+		delay *= 2
+	}
+
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	return rand.N(delay + 1)
+}
+
+// sleep waits for delay, returning false early if ctx is done.
+func sleep(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
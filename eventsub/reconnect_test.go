@@ -0,0 +1,89 @@
+package twitcheventsub_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	twitcheventsub "github.com/v0idzzy/twitch-helix/eventsub"
+	"github.com/v0idzzy/twitch-helix/eventsub/eventsubtest"
+)
+
+func TestReconnectKeepsSession(t *testing.T) {
+	server := eventsubtest.NewServer()
+	defer server.Close()
+
+	events := make(chan twitcheventsub.Event, 64)
+	config := server.NewSessionConfig(events)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	runCtx, stop := context.WithCancel(ctx)
+	stopped := make(chan error, 1)
+
+	go func() {
+		stopped <- config.Run(runCtx)
+	}()
+
+	defer func() {
+		stop()
+
+		if err := <-stopped; !errors.Is(err, context.Canceled) {
+			t.Errorf("Run = %v, want %v", err, context.Canceled)
+		}
+	}()
+
+	session, err := server.WaitSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := session.Reconnect(ctx); err != nil {
+		t.Fatalf("Reconnect: %v", err)
+	}
+
+	if err := session.Notify("stream.online", "1", map[string]string{"broadcaster_user_id": "1"}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	welcomes := 0
+
+	for notified := false; !notified; {
+		select {
+		case <-ctx.Done():
+			t.Fatal("notification sent after the reconnect was not delivered")
+		case event := <-events:
+			switch event.MessageType {
+			case "session_welcome":
+				welcomes++
+			case "notification":
+				notified = event.SubscriptionType == "stream.online"
+			}
+		}
+	}
+
+	if welcomes != 1 {
+		t.Errorf("received %d session_welcome events, want only the one of the new session", welcomes)
+	}
+
+	if id := config.SessionID(); id != session.ID() {
+		t.Errorf("SessionID = %q after reconnect, want %q", id, session.ID())
+	}
+
+	if connections := session.Connections(); connections != 2 {
+		t.Errorf("session had %d connections, want 2", connections)
+	}
+
+	if state := config.State(); state != twitcheventsub.StateConnected {
+		t.Errorf("State = %v after reconnect, want %v", state, twitcheventsub.StateConnected)
+	}
+
+	waitCtx, cancelWait := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancelWait()
+
+	if other, err := server.WaitSession(waitCtx); err == nil {
+		t.Errorf("reconnect opened new session %q", other.ID())
+	}
+}
@@ -0,0 +1,368 @@
+package twitcheventsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	twitchhelix "github.com/v0idzzy/twitch-helix"
+	"go.uber.org/zap"
+)
+
+// ErrSubscriptionRevoked is the error of a subscription Twitch revoked.
+var ErrSubscriptionRevoked = errors.New("subscription revoked")
+
+// teardownTimeout bounds deleting the subscriptions of a SubscriptionSet
+// when Run stops.
+const teardownTimeout = 5 * time.Second
+
+// SubscriptionClient creates and deletes EventSub subscriptions.
+// *twitchhelix.Client implements it.
+type SubscriptionClient interface {
+	// CreateEventSubSubscription creates the subscription described by req.
+	CreateEventSubSubscription(ctx context.Context, req twitchhelix.EventRequest) (*twitchhelix.EventSubSubscriptionResponse, error)
+
+	// DeleteEventSubSubscription deletes a subscription.
+	DeleteEventSubSubscription(ctx context.Context, req twitchhelix.RequestDeleteEventSubSubscription) error
+}
+
+// SubscriptionSet is a set of EventSub subscriptions kept alive for a
+// SessionConfig it is attached to with SetSubscriptions.
+//
+// WebSocket subscriptions end with their session, so the set creates every
+// subscription on each new session. Sessions moved by a session_reconnect
+// message keep their subscriptions. Failed creations are retried according
+// to the retry policy of the set, and the subscriptions are deleted when
+// Run stops.
+//
+// SubscriptionSet is safe for concurrent use.
+type SubscriptionSet struct {
+	// client creates and deletes the subscriptions.
+	client SubscriptionClient
+
+	// changed receives a value when subscriptions were added.
+	changed chan struct{}
+
+	// mu guards the fields below.
+	mu sync.Mutex
+
+	// logger logs failed creations and deletions.
+	logger *zap.Logger
+
+	// retryPolicy controls retries of failed creations.
+	retryPolicy *ReconnectPolicy
+
+	// entries are the subscriptions of the set, in the order they were
+	// added.
+	entries []*subscriptionEntry
+
+	// totalCost is the total cost reported by the last creation.
+	totalCost int
+
+	// maxTotalCost is the maximum total cost reported by the last creation.
+	maxTotalCost int
+}
+
+// subscriptionEntry is a subscription of a SubscriptionSet.
+type subscriptionEntry struct {
+	// status is the status of the subscription.
+	status SubscriptionStatus
+
+	// failed reports whether creating the subscription on the current
+	// session was given up.
+	failed bool
+}
+
+// SubscriptionStatus describes a subscription of a SubscriptionSet.
+type SubscriptionStatus struct {
+	// Type is the subscription type, such as "stream.online".
+	Type string
+
+	// Version is the version of the subscription type.
+	Version string
+
+	// Condition is the condition of the subscription.
+	Condition any
+
+	// ID is the ID of the subscription, empty while it is not created on
+	// the current session.
+	ID string
+
+	// Status is the status reported by Twitch, such as "enabled" or the
+	// reason of a revocation, empty while the subscription is not created.
+	Status string
+
+	// Cost is how much the subscription counts against the cost limit.
+	Cost int
+
+	// Attempts is the number of failed attempts to create the subscription
+	// on the current session.
+	Attempts int
+
+	// Err is the error of the last failed attempt, or ErrSubscriptionRevoked
+	// if Twitch revoked the subscription. It is nil once the subscription
+	// is created.
+	Err error
+}
+
+// NewSubscriptionSet returns an empty SubscriptionSet creating its
+// subscriptions with client.
+func NewSubscriptionSet(client SubscriptionClient) *SubscriptionSet {
+	return &SubscriptionSet{
+		client:      client,
+		changed:     make(chan struct{}, 1),
+		logger:      zap.L(),
+		retryPolicy: defaultSubscriptionRetryPolicy(),
+	}
+}
+
+// defaultSubscriptionRetryPolicy returns the retry policy of a new
+// SubscriptionSet.
+func defaultSubscriptionRetryPolicy() *ReconnectPolicy {
+	return &ReconnectPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   time.Second,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+// SetLogger makes the set log to logger instead of the global zap logger.
+func (set *SubscriptionSet) SetLogger(logger *zap.Logger) {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	set.logger = logger
+}
+
+// SetRetryPolicy sets how failed creations are retried on a session.
+// MaxAttempts limits the attempts per subscription, and BaseDelay and
+// MaxDelay bound the delay between two rounds of attempts. Errors other
+// than rate limits, server and network errors are not retried.
+//
+// By default 5 attempts are made, waiting up to 30 seconds between them.
+// A nil policy restores the default.
+func (set *SubscriptionSet) SetRetryPolicy(policy *ReconnectPolicy) {
+	if policy == nil {
+		policy = defaultSubscriptionRetryPolicy()
+	}
+
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	set.retryPolicy = policy
+}
+
+// Add adds the subscription of subscriptionType and version with condition,
+// such as a twitchhelix.ConditionStreamOnline, to the set. It is created
+// right away if a session is open.
+func (set *SubscriptionSet) Add(subscriptionType, version string, condition any) {
+	set.mu.Lock()
+	set.entries = append(set.entries, &subscriptionEntry{
+		status: SubscriptionStatus{
+			Type:      subscriptionType,
+			Version:   version,
+			Condition: condition,
+		},
+	})
+	set.mu.Unlock()
+
+	select {
+	case set.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Status returns the status of every subscription of the set, in the order
+// they were added.
+func (set *SubscriptionSet) Status() []SubscriptionStatus {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	statuses := make([]SubscriptionStatus, len(set.entries))
+	for i, entry := range set.entries {
+		statuses[i] = entry.status
+	}
+
+	return statuses
+}
+
+// Cost returns the total cost of the subscriptions of the client ID and
+// its maximum, as reported when a subscription was last created.
+func (set *SubscriptionSet) Cost() (total, maxTotal int) {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	return set.totalCost, set.maxTotalCost
+}
+
+// run creates the subscriptions of the set on the session sessionID until
+// ctx is done, which it is when the session ends.
+func (set *SubscriptionSet) run(ctx context.Context, sessionID string) {
+	for {
+		set.create(ctx, sessionID)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-set.changed:
+		}
+	}
+}
+
+// create creates the subscriptions not created on the session sessionID
+// yet, retrying failed ones.
+func (set *SubscriptionSet) create(ctx context.Context, sessionID string) {
+	for round := 0; ; round++ {
+		pending, policy := set.pending()
+		if len(pending) == 0 {
+			return
+		}
+
+		for _, entry := range pending {
+			set.mu.Lock()
+			req := twitchhelix.EventRequest{
+				Type:      entry.status.Type,
+				Version:   entry.status.Version,
+				Conditoin: entry.status.Condition,
+				Transport: twitchhelix.WebsocketTransport{
+					Method:    "websocket",
+					SessionID: sessionID,
+				},
+			}
+			set.mu.Unlock()
+
+			resp, err := set.client.CreateEventSubSubscription(ctx, req)
+			if ctx.Err() != nil {
+				return
+			}
+
+			set.record(entry, policy, resp, err)
+		}
+
+		if pending, _ := set.pending(); len(pending) == 0 || !sleep(ctx, policy.backoff(round)) {
+			return
+		}
+	}
+}
+
+// pending returns the entries to be created on the current session and the
+// retry policy to create them with.
+func (set *SubscriptionSet) pending() (pending []*subscriptionEntry, policy *ReconnectPolicy) {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	for _, entry := range set.entries {
+		if entry.status.ID == "" && !entry.failed {
+			pending = append(pending, entry)
+		}
+	}
+
+	return pending, set.retryPolicy
+}
+
+// record records the outcome of creating the subscription of entry, giving
+// up after the attempts allowed by policy.
+func (set *SubscriptionSet) record(entry *subscriptionEntry, policy *ReconnectPolicy, resp *twitchhelix.EventSubSubscriptionResponse, err error) {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	if err == nil && (resp == nil || len(resp.Data) == 0) {
+		err = errors.New("no subscription in response")
+	}
+
+	if err != nil {
+		entry.status.Attempts++
+		entry.status.Err = err
+
+		entry.failed = !retryable(err) || (policy.MaxAttempts > 0 && entry.status.Attempts >= policy.MaxAttempts)
+
+		set.logger.Warn("failed to create eventsub subscription",
+			zap.String("subscription_type", entry.status.Type),
+			zap.Int("attempts", entry.status.Attempts),
+			zap.Bool("giving_up", entry.failed),
+			zap.Error(err))
+
+		return
+	}
+
+	created := resp.Data[0]
+	entry.status.ID = created.ID
+	entry.status.Status = created.Status
+	entry.status.Cost = created.Cost
+	entry.status.Err = nil
+
+	set.totalCost = resp.TotalCost
+	set.maxTotalCost = resp.MaxTotalCost
+}
+
+// revoke records that Twitch revoked the subscription id for reason. It
+// is not created again on the current session.
+func (set *SubscriptionSet) revoke(id, reason string) {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	for _, entry := range set.entries {
+		if entry.status.ID == id {
+			entry.status.ID = ""
+			entry.status.Status = reason
+			entry.status.Err = fmt.Errorf("%w: %s", ErrSubscriptionRevoked, reason)
+			entry.failed = true
+		}
+	}
+}
+
+// reset records that the session of the subscriptions ended, so they have
+// to be created on the next one.
+func (set *SubscriptionSet) reset() {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	for _, entry := range set.entries {
+		entry.status.ID = ""
+		entry.status.Status = ""
+		entry.status.Cost = 0
+		entry.status.Attempts = 0
+		entry.failed = false
+	}
+}
+
+// teardown deletes the created subscriptions and resets the set.
+func (set *SubscriptionSet) teardown(ctx context.Context) {
+	set.mu.Lock()
+	var ids []string
+	for _, entry := range set.entries {
+		if entry.status.ID != "" {
+			ids = append(ids, entry.status.ID)
+		}
+	}
+	logger := set.logger
+	set.mu.Unlock()
+
+	for _, id := range ids {
+		err := set.client.DeleteEventSubSubscription(ctx, twitchhelix.RequestDeleteEventSubSubscription{ID: id})
+		if err != nil {
+			logger.Warn("failed to delete eventsub subscription", zap.String("subscription_id", id), zap.Error(err))
+		}
+	}
+
+	set.reset()
+}
+
+// retryable reports whether creating a subscription that failed with err
+// may succeed when attempted again.
+func retryable(err error) bool {
+	var scopeErr *twitchhelix.ScopeError
+	if errors.As(err, &scopeErr) {
+		return false
+	}
+
+	var apiErr *twitchhelix.TwitchAPIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	return true
+}
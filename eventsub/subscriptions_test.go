@@ -0,0 +1,60 @@
+package twitcheventsub_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	twitchhelix "github.com/v0idzzy/twitch-helix"
+	twitcheventsub "github.com/v0idzzy/twitch-helix/eventsub"
+	"github.com/v0idzzy/twitch-helix/eventsub/eventsubtest"
+)
+
+// emptyClient is a SubscriptionClient whose creations succeed without a
+// response.
+type emptyClient struct{}
+
+func (emptyClient) CreateEventSubSubscription(context.Context, twitchhelix.EventRequest) (*twitchhelix.EventSubSubscriptionResponse, error) {
+	return nil, nil
+}
+
+func (emptyClient) DeleteEventSubSubscription(context.Context, twitchhelix.RequestDeleteEventSubSubscription) error {
+	return nil
+}
+
+func TestSubscriptionSetWithoutResponse(t *testing.T) {
+	server := eventsubtest.NewServer()
+	defer server.Close()
+
+	set := twitcheventsub.NewSubscriptionSet(emptyClient{})
+	set.SetRetryPolicy(&twitcheventsub.ReconnectPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond})
+	set.Add("stream.online", "1", map[string]string{"broadcaster_user_id": "1"})
+
+	runSession(t, server, func(config *twitcheventsub.SessionConfig) {
+		config.SetSubscriptions(set)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := server.WaitSession(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for {
+		status := set.Status()[0]
+		if status.Attempts == 2 {
+			if status.Err == nil || status.ID != "" {
+				t.Errorf("status = %+v, want a failed creation", status)
+			}
+
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			t.Fatalf("creation without response was not recorded as failed: %+v", status)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
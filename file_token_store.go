@@ -0,0 +1,227 @@
+package twitchhelix
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// pbkdf2Iterations is the PBKDF2-HMAC-SHA256 iteration count used to derive
+// the encryption key from the passphrase.
+const pbkdf2Iterations = 100_000
+
+// FileTokenStore is a TokenStore that persists the token as JSON in a file.
+//
+// The file is written atomically with 0600 permissions. If a passphrase is
+// set, the token is encrypted with AES-256-GCM using a key derived from the
+// passphrase.
+//
+// The file is only read by the first Load. The token is then kept in memory
+// and replaced by Save, so requests do not read and decrypt the file again.
+// Changes made to the file by other processes are not seen.
+type FileTokenStore struct {
+	// path is the file the token is stored in.
+	path string
+
+	// passphrase encrypts the file contents. Empty means plain JSON.
+	passphrase string
+
+	// mu serializes reads and writes of the file.
+	mu sync.Mutex
+
+	// loaded reports whether token holds the contents of the file.
+	loaded bool
+
+	// token is the stored token, nil if none. The pointed to token is
+	// never modified.
+	token *NewToken
+}
+
+// encryptedToken is the on-disk format of an encrypted token.
+type encryptedToken struct {
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// NewFileTokenStore returns a FileTokenStore that stores the token at path.
+//
+// passphrase can be empty to store the token unencrypted.
+func NewFileTokenStore(path, passphrase string) *FileTokenStore {
+	return &FileTokenStore{
+		path:       path,
+		passphrase: passphrase,
+	}
+}
+
+// Load returns a copy of the token, reading it from the file on first use.
+//
+// It returns nil and no error if the file does not exist.
+func (s *FileTokenStore) Load() (*NewToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.loaded {
+		token, err := s.read()
+		if err != nil {
+			return nil, err
+		}
+
+		s.token = token
+		s.loaded = true
+	}
+
+	return s.token.clone(), nil
+}
+
+// read reads the token from the file.
+func (s *FileTokenStore) read() (*NewToken, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	if s.passphrase != "" {
+		data, err = s.decrypt(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var token NewToken
+
+	err = json.Unmarshal(data, &token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// Save writes token to the file and keeps a copy of it in memory.
+//
+// The token is written to a temporary file in the same directory which then
+// replaces the old file, so readers never see a partial write.
+func (s *FileTokenStore) Save(token *NewToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to marshal token: %w", err)
+	}
+
+	if s.passphrase != "" {
+		data, err = s.encrypt(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary token file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	err = tmp.Chmod(0o600)
+	if err == nil {
+		_, err = tmp.Write(data)
+	}
+
+	if err == nil {
+		err = tmp.Sync()
+	}
+
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
+
+	err = os.Rename(tmp.Name(), s.path)
+	if err != nil {
+		return fmt.Errorf("failed to replace token file: %w", err)
+	}
+
+	s.token = token.clone()
+	s.loaded = true
+
+	return nil
+}
+
+// encrypt seals plaintext with a key derived from the passphrase and a fresh salt.
+func (s *FileTokenStore) encrypt(plaintext []byte) ([]byte, error) {
+	salt := make([]byte, 16)
+	rand.Read(salt)
+
+	gcm, err := s.cipher(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	rand.Read(nonce)
+
+	return json.Marshal(encryptedToken{
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, nil),
+	})
+}
+
+// decrypt opens data written by encrypt.
+func (s *FileTokenStore) decrypt(data []byte) ([]byte, error) {
+	var sealed encryptedToken
+
+	err := json.Unmarshal(data, &sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal encrypted token: %w", err)
+	}
+
+	gcm, err := s.cipher(sealed.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed.Nonce) != gcm.NonceSize() {
+		return nil, errors.New("failed to decrypt token: invalid nonce")
+	}
+
+	plaintext, err := gcm.Open(nil, sealed.Nonce, sealed.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt token: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+// cipher returns the AES-256-GCM cipher for the passphrase and salt.
+func (s *FileTokenStore) cipher(salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, s.passphrase, salt, pbkdf2Iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
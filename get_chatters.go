@@ -2,6 +2,7 @@ package twitchhelix
 
 import (
 	"context"
+	"iter"
 
 	"github.com/google/go-querystring/query"
 )
@@ -53,6 +54,8 @@ type Chatter struct {
 }
 
 // GetChatters gets the users connected to twitch's IRC chat client.
+//
+// Requires a user access token with the moderator:read:chatters scope.
 func (c *Client) GetChatters(ctx context.Context, req ChattersRequest) (*ChattersResponse, error) {
 	var resp ChattersResponse
 
@@ -71,3 +74,21 @@ func (c *Client) GetChatters(ctx context.Context, req ChattersRequest) (*Chatter
 
 	return &resp, nil
 }
+
+// AllChatters returns an iterator over every chatter in the broadcaster's
+// chat, starting at req.After.
+func (c *Client) AllChatters(ctx context.Context, req ChattersRequest) iter.Seq2[Chatter, error] {
+	cursor, _ := startCursor(req.After, nil)
+
+	return Paginate(ctx, cursor, func(ctx context.Context, cursor string) ([]Chatter, string, error) {
+		req := req
+		req.After, _ = pageCursors(cursor, false)
+
+		resp, err := c.GetChatters(ctx, req)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return resp.Chatters, resp.Pagination.Cursor, nil
+	})
+}
@@ -2,6 +2,7 @@ package twitchhelix
 
 import (
 	"context"
+	"iter"
 
 	"github.com/google/go-querystring/query"
 )
@@ -112,6 +113,8 @@ type StreamData struct {
 
 // GetStreams retrieves a list of live streams.
 // Results can be filtered using the provided query parameters.
+//
+// Accepts a user or app access token.
 func (c *Client) GetStreams(ctx context.Context, req StreamRequest) (*StreamResponse, error) {
 	var resp StreamResponse
 
@@ -129,3 +132,24 @@ func (c *Client) GetStreams(ctx context.Context, req StreamRequest) (*StreamResp
 
 	return &resp, nil
 }
+
+// AllStreams returns an iterator over the live streams matching req across
+// every page.
+//
+// Paging starts at req.After, or walks backwards from req.Before if only
+// Before is set.
+func (c *Client) AllStreams(ctx context.Context, req StreamRequest) iter.Seq2[*StreamData, error] {
+	cursor, backward := startCursor(req.After, req.Before)
+
+	return Paginate(ctx, cursor, func(ctx context.Context, cursor string) ([]*StreamData, string, error) {
+		req := req
+		req.After, req.Before = pageCursors(cursor, backward)
+
+		resp, err := c.GetStreams(ctx, req)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return resp.Data, resp.Pagination.Cursor, nil
+	})
+}
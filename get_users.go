@@ -70,7 +70,20 @@ type User struct {
 
 // GetUsers retrieves information about one or more Twitch users.
 // Users can be looked up by ID, login name, or both.
+//
+// If the client has a Cache, cached users are not requested again.
+//
+// Accepts a user or app access token.
 func (c *Client) GetUsers(ctx context.Context, req GetUsersRequest) (*GetUsersResponse, error) {
+	if cache := c.Cache(); cache != nil {
+		return cache.getUsers(ctx, req, c.getUsers)
+	}
+
+	return c.getUsers(ctx, req)
+}
+
+// getUsers requests users from Twitch, bypassing the cache.
+func (c *Client) getUsers(ctx context.Context, req GetUsersRequest) (*GetUsersResponse, error) {
 	var resp GetUsersResponse
 
 	values, err := query.Values(req)
@@ -0,0 +1,916 @@
+package helixtest
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	twitchhelix "github.com/v0idzzy/twitch-helix"
+)
+
+// Limits enforced on created resources, the ones of Twitch.
+const (
+	maxRewards            = 50
+	maxRewardTitle        = 45
+	maxPollTitle          = 60
+	maxPollChoiceTitle    = 25
+	minPollChoices        = 2
+	maxPollChoices        = 5
+	minPollDuration       = 15
+	maxPollDuration       = 1800
+	maxChatMessage        = 500
+	maxTags               = 10
+	maxTagLength          = 25
+	defaultRewardColor    = "#9147FF"
+	defaultRewardImageURL = "https://static-cdn.jtvnw.net/custom-reward-images/default-%s.png"
+)
+
+// getUsers serves GetUsers.
+func (s *Server) getUsers(c *call) (any, error) {
+	ids := c.query["id"]
+	logins := c.query["login"]
+
+	if len(ids)+len(logins) > maxIDs {
+		return nil, errorf(http.StatusBadRequest, "The sum of the number of ids and logins must not exceed %d", maxIDs)
+	}
+
+	if len(ids) == 0 && len(logins) == 0 {
+		if c.token.UserID == "" {
+			return nil, errorf(http.StatusBadRequest, "Missing required parameter id or login")
+		}
+
+		ids = []string{c.token.UserID}
+	}
+
+	resp := twitchhelix.GetUsersResponse{Data: []twitchhelix.User{}}
+
+	for _, user := range s.users {
+		if !slices.Contains(ids, user.ID) && !slices.ContainsFunc(logins, func(login string) bool { return strings.EqualFold(login, user.Login) }) {
+			continue
+		}
+
+		if user.ID != c.token.UserID || !slices.Contains(c.token.Scopes, "user:read:email") {
+			user.Email = ""
+		}
+
+		resp.Data = append(resp.Data, user)
+	}
+
+	return resp, nil
+}
+
+// getStreams serves GetStreams.
+func (s *Server) getStreams(c *call) (any, error) {
+	filters := make(map[string][]string)
+
+	for _, name := range []string{"user_id", "user_login", "game_id", "language"} {
+		values, err := c.capped(name, maxIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		filters[name] = values
+	}
+
+	streamType := cmp.Or(c.query.Get("type"), "all")
+	if streamType != "all" && streamType != "live" {
+		return nil, errorf(http.StatusBadRequest, "The parameter \"type\" must be all or live")
+	}
+
+	matches := func(values []string, value string) bool {
+		return len(values) == 0 || slices.Contains(values, value)
+	}
+
+	var streams []Stream
+
+	for _, stream := range s.streams {
+		user, _ := s.user(stream.UserID)
+
+		if matches(filters["user_id"], stream.UserID) &&
+			(len(filters["user_login"]) == 0 || slices.ContainsFunc(filters["user_login"], func(login string) bool { return strings.EqualFold(login, user.Login) })) &&
+			matches(filters["game_id"], stream.GameID) &&
+			matches(filters["language"], stream.Language) {
+			streams = append(streams, stream)
+		}
+	}
+
+	// Twitch lists the streams with the most viewers first.
+	slices.SortStableFunc(streams, func(a, b Stream) int { return b.ViewerCount - a.ViewerCount })
+
+	start, end, cursor, err := c.page(len(streams), 20, 100)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := twitchhelix.StreamResponse{
+		Data:       []*twitchhelix.StreamData{},
+		Pagination: twitchhelix.Pagination{Cursor: cursor},
+	}
+
+	for _, stream := range streams[start:end] {
+		resp.Data = append(resp.Data, s.streamData(stream))
+	}
+
+	return resp, nil
+}
+
+// streamData returns stream as reported by GetStreams.
+func (s *Server) streamData(stream Stream) *twitchhelix.StreamData {
+	user, _ := s.user(stream.UserID)
+
+	tags := make([]*string, 0, len(stream.Tags))
+	for _, tag := range stream.Tags {
+		tags = append(tags, ptr(tag))
+	}
+
+	return &twitchhelix.StreamData{
+		ID:           ptr(stream.ID),
+		UserID:       ptr(stream.UserID),
+		UserLogin:    ptr(user.Login),
+		UserName:     ptr(user.DisplayName),
+		GameID:       ptr(stream.GameID),
+		GameName:     ptr(stream.GameName),
+		Type:         ptr("live"),
+		Title:        ptr(stream.Title),
+		Tags:         tags,
+		ViewerCount:  ptr(stream.ViewerCount),
+		StartedAt:    ptr(formatTime(stream.StartedAt)),
+		Language:     ptr(stream.Language),
+		ThumbnailURL: ptr(fmt.Sprintf("https://static-cdn.jtvnw.net/previews-ttv/live_user_%s-{width}x{height}.jpg", user.Login)),
+		IsMature:     ptr(stream.IsMature),
+	}
+}
+
+// searchChannels serves SearchChannels.
+func (s *Server) searchChannels(c *call) (any, error) {
+	query, err := c.required("query")
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.ToLower(query)
+	liveOnly := c.query.Get("live_only") == "true"
+
+	var channels []twitchhelix.Channel
+
+	for _, user := range s.users {
+		if !strings.Contains(strings.ToLower(user.Login), query) && !strings.Contains(strings.ToLower(user.DisplayName), query) {
+			continue
+		}
+
+		stream, live := s.stream(user.ID)
+		if liveOnly && !live {
+			continue
+		}
+
+		channel := s.channels[user.ID]
+
+		result := twitchhelix.Channel{
+			BroadcasterLanguage: channel.Language,
+			BroadcasterLogin:    user.Login,
+			DisplayName:         user.DisplayName,
+			GameID:              channel.GameID,
+			GameName:            channel.GameName,
+			ID:                  user.ID,
+			IsLive:              live,
+			Tags:                nonNil(slices.Clone(channel.Tags)),
+			ThumbnailURL:        user.ProfileImageURL,
+			Title:               channel.Title,
+		}
+
+		if live {
+			result.StartedAt = formatTime(stream.StartedAt)
+		}
+
+		channels = append(channels, result)
+	}
+
+	start, end, cursor, err := c.page(len(channels), 20, 100)
+	if err != nil {
+		return nil, err
+	}
+
+	return twitchhelix.ResponseSearchChannels{
+		Data:       nonNil(channels[start:end]),
+		Pagination: &twitchhelix.Pagination{Cursor: cursor},
+	}, nil
+}
+
+// modifyChannelInformation serves ModifyChannelInformation.
+func (s *Server) modifyChannelInformation(c *call) (any, error) {
+	broadcasterID, err := c.required("broadcaster_id")
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.owner(broadcasterID, "broadcaster_id")
+	if err != nil {
+		return nil, err
+	}
+
+	var req twitchhelix.RequestModifyChannelInformation
+
+	err = c.decode(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req == (twitchhelix.RequestModifyChannelInformation{}) {
+		return nil, errorf(http.StatusBadRequest, "The request must update at least one field")
+	}
+
+	if req.Title != nil && *req.Title == "" {
+		return nil, errorf(http.StatusBadRequest, "The title may not be empty")
+	}
+
+	if req.Tags != nil {
+		if len(*req.Tags) > maxTags {
+			return nil, errorf(http.StatusBadRequest, "A channel may have at most %d tags", maxTags)
+		}
+
+		for _, tag := range *req.Tags {
+			if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+				return nil, errorf(http.StatusBadRequest, "Tags must be between 1 and %d characters long", maxTagLength)
+			}
+		}
+	}
+
+	channel, ok := s.channels[broadcasterID]
+	if !ok {
+		channel = &Channel{BroadcasterID: broadcasterID}
+		s.channels[broadcasterID] = channel
+	}
+
+	if req.GameID != nil {
+		channel.GameID = *req.GameID
+	}
+
+	if req.BroadcasterLanguage != nil {
+		channel.Language = *req.BroadcasterLanguage
+	}
+
+	if req.Title != nil {
+		channel.Title = *req.Title
+	}
+
+	if req.Delay != nil {
+		channel.Delay = *req.Delay
+	}
+
+	if req.Tags != nil {
+		channel.Tags = slices.Clone(*req.Tags)
+	}
+
+	if req.IsBrandedContent != nil {
+		channel.IsBrandedContent = *req.IsBrandedContent
+	}
+
+	// A live stream shows the new channel information right away.
+	for i, stream := range s.streams {
+		if stream.UserID == broadcasterID {
+			s.streams[i].GameID = channel.GameID
+			s.streams[i].Title = channel.Title
+			s.streams[i].Language = channel.Language
+			s.streams[i].Tags = slices.Clone(channel.Tags)
+		}
+	}
+
+	return nil, nil
+}
+
+// getChatters serves GetChatters.
+func (s *Server) getChatters(c *call) (any, error) {
+	broadcasterID, err := c.required("broadcaster_id")
+	if err != nil {
+		return nil, err
+	}
+
+	moderatorID, err := c.required("moderator_id")
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.owner(moderatorID, "moderator_id")
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.moderates(moderatorID, broadcasterID) {
+		return nil, errorf(http.StatusForbidden, "The user in moderator_id must be a moderator of the broadcaster")
+	}
+
+	chatters := s.chatters[broadcasterID]
+
+	start, end, cursor, err := c.page(len(chatters), 100, 1000)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := twitchhelix.ChattersResponse{
+		Chatters:   []twitchhelix.Chatter{},
+		Pagination: twitchhelix.Pagination{Cursor: cursor},
+		Total:      len(chatters),
+	}
+
+	for _, userID := range chatters[start:end] {
+		user, _ := s.user(userID)
+
+		resp.Chatters = append(resp.Chatters, twitchhelix.Chatter{
+			UserID:    userID,
+			UserLogin: user.Login,
+			UserName:  user.DisplayName,
+		})
+	}
+
+	return resp, nil
+}
+
+// moderates reports whether userID is broadcasterID or one of their
+// moderators.
+func (s *Server) moderates(userID, broadcasterID string) bool {
+	return userID == broadcasterID || slices.Contains(s.moderators[broadcasterID], userID)
+}
+
+// sendMessage serves SendMessage.
+func (s *Server) sendMessage(c *call) (any, error) {
+	var req twitchhelix.SendMessageRequest
+
+	err := c.decode(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case req.BroadcasterID == "":
+		return nil, errorf(http.StatusBadRequest, "Missing required parameter \"broadcaster_id\"")
+	case req.SenderID == "":
+		return nil, errorf(http.StatusBadRequest, "Missing required parameter \"sender_id\"")
+	case req.Message == "":
+		return nil, errorf(http.StatusBadRequest, "Missing required parameter \"message\"")
+	case utf8.RuneCountInString(req.Message) > maxChatMessage:
+		return nil, errorf(http.StatusBadRequest, "The message may not be longer than %d characters", maxChatMessage)
+	}
+
+	// App access tokens send as the bot account that authorized them.
+	if c.token.UserID != "" {
+		err = c.owner(req.SenderID, "sender_id")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	message := ChatMessage{
+		ID:            newID(),
+		BroadcasterID: req.BroadcasterID,
+		SenderID:      req.SenderID,
+		Message:       req.Message,
+		SentAt:        time.Now(),
+	}
+
+	if req.ReplyParentMessageID != nil {
+		message.ReplyParentMessageID = *req.ReplyParentMessageID
+	}
+
+	s.messages = append(s.messages, message)
+
+	return data[twitchhelix.SendMessageResponse]{Data: []twitchhelix.SendMessageResponse{{
+		MessageID: message.ID,
+		IsSent:    true,
+	}}}, nil
+}
+
+// sendShoutout serves SendShoutout.
+func (s *Server) sendShoutout(c *call) (any, error) {
+	fromID, err := c.required("from_broadcaster_id")
+	if err != nil {
+		return nil, err
+	}
+
+	toID, err := c.required("to_broadcaster_id")
+	if err != nil {
+		return nil, err
+	}
+
+	moderatorID, err := c.required("moderator_id")
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.owner(moderatorID, "moderator_id")
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.moderates(moderatorID, fromID) {
+		return nil, errorf(http.StatusForbidden, "The user in moderator_id must be a moderator of the broadcaster")
+	}
+
+	if fromID == toID {
+		return nil, errorf(http.StatusBadRequest, "The broadcaster may not give themselves a Shoutout")
+	}
+
+	if _, live := s.stream(fromID); !live {
+		return nil, errorf(http.StatusBadRequest, "The broadcaster is not streaming live")
+	}
+
+	now := time.Now()
+
+	for _, shoutout := range s.shoutouts {
+		if shoutout.FromBroadcasterID != fromID {
+			continue
+		}
+
+		if now.Sub(shoutout.SentAt) < shoutoutCooldown {
+			return nil, errorf(http.StatusTooManyRequests, "The broadcaster may not give another Shoutout within 2 minutes")
+		}
+
+		if shoutout.ToBroadcasterID == toID && now.Sub(shoutout.SentAt) < shoutoutTargetCooldown {
+			return nil, errorf(http.StatusTooManyRequests, "The broadcaster may not give the same broadcaster another Shoutout within 60 minutes")
+		}
+	}
+
+	s.shoutouts = append(s.shoutouts, Shoutout{
+		FromBroadcasterID: fromID,
+		ToBroadcasterID:   toID,
+		ModeratorID:       moderatorID,
+		SentAt:            now,
+	})
+
+	return nil, nil
+}
+
+// getSubscriptions serves GetSubscriptions.
+func (s *Server) getSubscriptions(c *call) (any, error) {
+	broadcasterID, err := c.required("broadcaster_id")
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.owner(broadcasterID, "broadcaster_id")
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs, err := c.capped("user_id", maxIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		subs   []Subscription
+		total  int
+		points int
+	)
+
+	for _, sub := range s.subscriptions {
+		if sub.BroadcasterID != broadcasterID {
+			continue
+		}
+
+		total++
+		points += tierPoints(sub.Tier)
+
+		if len(userIDs) == 0 || slices.Contains(userIDs, sub.UserID) {
+			subs = append(subs, sub)
+		}
+	}
+
+	start, end, cursor, err := c.page(len(subs), 20, 100)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := twitchhelix.GetSubscriptionsResponse{
+		Data:       []twitchhelix.SubscriptionDataV2{},
+		Pagination: &twitchhelix.Pagination{Cursor: cursor},
+		Points:     points,
+		Total:      total,
+	}
+
+	for _, sub := range subs[start:end] {
+		broadcaster, _ := s.user(sub.BroadcasterID)
+		user, _ := s.user(sub.UserID)
+
+		data := twitchhelix.SubscriptionDataV2{
+			BroadcasterID:    sub.BroadcasterID,
+			BroadcasterLogin: broadcaster.Login,
+			BroadcasterName:  broadcaster.DisplayName,
+			UserID:           sub.UserID,
+			UserLogin:        user.Login,
+			UserName:         user.DisplayName,
+			Tier:             sub.Tier,
+			PlanName:         "Channel Subscription (" + broadcaster.Login + ")",
+			IsGift:           sub.GifterID != "",
+		}
+
+		data.GifterID, data.GifterLogin, data.GifterName = s.gifter(sub)
+
+		resp.Data = append(resp.Data, data)
+	}
+
+	return resp, nil
+}
+
+// checkSubscription serves CheckSubscription.
+func (s *Server) checkSubscription(c *call) (any, error) {
+	broadcasterID, err := c.required("broadcaster_id")
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := c.required("user_id")
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.owner(userID, "user_id")
+	if err != nil {
+		return nil, err
+	}
+
+	i := slices.IndexFunc(s.subscriptions, func(sub Subscription) bool {
+		return sub.BroadcasterID == broadcasterID && sub.UserID == userID
+	})
+	if i < 0 {
+		return nil, errorf(http.StatusNotFound, "%s has no subscription to %s", userID, broadcasterID)
+	}
+
+	sub := s.subscriptions[i]
+	broadcaster, _ := s.user(broadcasterID)
+
+	data := twitchhelix.SubscriptionData{
+		BroadcasterID:    broadcasterID,
+		BroadcasterLogin: broadcaster.Login,
+		BroadcasterName:  broadcaster.DisplayName,
+		Tier:             sub.Tier,
+		IsGift:           sub.GifterID != "",
+	}
+
+	data.GifterID, data.GifterLogin, data.GifterName = s.gifter(sub)
+
+	return twitchhelix.CheckSubscriptionResponse{Data: []twitchhelix.SubscriptionData{data}}, nil
+}
+
+// gifter returns the ID, login and name of the gifter of sub, nil if it
+// was not a gift.
+func (s *Server) gifter(sub Subscription) (id, login, name *string) {
+	if sub.GifterID == "" {
+		return nil, nil, nil
+	}
+
+	gifter, _ := s.user(sub.GifterID)
+
+	return ptr(sub.GifterID), ptr(gifter.Login), ptr(gifter.DisplayName)
+}
+
+// tierPoints returns the subscriber points a subscription of tier is worth.
+func tierPoints(tier string) int {
+	switch tier {
+	case "2000":
+		return 2
+	case "3000":
+		return 6
+	}
+
+	return 1
+}
+
+// createCustomReward serves CreateCustomReward.
+func (s *Server) createCustomReward(c *call) (any, error) {
+	broadcasterID, err := c.required("broadcaster_id")
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.owner(broadcasterID, "broadcaster_id")
+	if err != nil {
+		return nil, err
+	}
+
+	var req twitchhelix.RequestCustomReward
+
+	err = c.decode(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case req.Title == nil:
+		return nil, errorf(http.StatusBadRequest, "Missing required parameter \"title\"")
+	case req.Cost == nil:
+		return nil, errorf(http.StatusBadRequest, "Missing required parameter \"cost\"")
+	case len(s.rewardsOf(broadcasterID)) >= maxRewards:
+		return nil, errorf(http.StatusBadRequest, "CREATE_CUSTOM_REWARD_TOO_MANY_REWARDS")
+	}
+
+	broadcaster, _ := s.user(broadcasterID)
+
+	reward := twitchhelix.Reward{
+		BroadcasterID:    broadcasterID,
+		BroadcasterLogin: broadcaster.Login,
+		BroadcasterName:  broadcaster.DisplayName,
+		ID:               newID(),
+		DefaultImage: twitchhelix.RewardImage{
+			URL1x: fmt.Sprintf(defaultRewardImageURL, "1"),
+			URL2x: fmt.Sprintf(defaultRewardImageURL, "2"),
+			URL4x: fmt.Sprintf(defaultRewardImageURL, "4"),
+		},
+		BackgroundColor: defaultRewardColor,
+		IsEnabled:       true,
+		IsInStock:       true,
+	}
+
+	err = s.applyReward(&reward, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.rewards = append(s.rewards, reward)
+
+	return twitchhelix.ResponseCustomReward{Data: []twitchhelix.Reward{reward}}, nil
+}
+
+// updateCustomReward serves UpdateCustomReward.
+func (s *Server) updateCustomReward(c *call) (any, error) {
+	broadcasterID, err := c.required("broadcaster_id")
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := c.required("id")
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.owner(broadcasterID, "broadcaster_id")
+	if err != nil {
+		return nil, err
+	}
+
+	var req twitchhelix.RequestCustomReward
+
+	err = c.decode(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	i := slices.IndexFunc(s.rewards, func(r twitchhelix.Reward) bool { return r.BroadcasterID == broadcasterID && r.ID == id })
+	if i < 0 {
+		return nil, errorf(http.StatusNotFound, "The custom reward was not found")
+	}
+
+	reward := s.rewards[i]
+
+	err = s.applyReward(&reward, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.rewards[i] = reward
+
+	return twitchhelix.ResponseCustomReward{Data: []twitchhelix.Reward{reward}}, nil
+}
+
+// applyReward validates the fields set in req and applies them to reward.
+func (s *Server) applyReward(reward *twitchhelix.Reward, req twitchhelix.RequestCustomReward) error {
+	if req.Title != nil {
+		title := *req.Title
+
+		if title == "" || utf8.RuneCountInString(title) > maxRewardTitle {
+			return errorf(http.StatusBadRequest, "The title must be between 1 and %d characters long", maxRewardTitle)
+		}
+
+		duplicate := slices.ContainsFunc(s.rewardsOf(reward.BroadcasterID), func(r twitchhelix.Reward) bool {
+			return r.ID != reward.ID && strings.EqualFold(r.Title, title)
+		})
+		if duplicate {
+			return errorf(http.StatusBadRequest, "CREATE_CUSTOM_REWARD_DUPLICATE_REWARD")
+		}
+
+		reward.Title = title
+	}
+
+	if req.Cost != nil {
+		if *req.Cost < 1 {
+			return errorf(http.StatusBadRequest, "The cost must be at least 1")
+		}
+
+		reward.Cost = *req.Cost
+	}
+
+	if req.Prompt != nil {
+		reward.Prompt = *req.Prompt
+	}
+
+	if req.BackgroundColor != nil {
+		reward.BackgroundColor = *req.BackgroundColor
+	}
+
+	if req.IsEnabled != nil {
+		reward.IsEnabled = *req.IsEnabled
+	}
+
+	if req.IsUserInputRequired != nil {
+		reward.IsUserInputRequired = *req.IsUserInputRequired
+	}
+
+	if req.IsMaxPerStreamEnabled != nil {
+		reward.MaxPerStreamSetting.IsEnabled = *req.IsMaxPerStreamEnabled
+	}
+
+	if req.MaxPerStream != nil {
+		reward.MaxPerStreamSetting.MaxPerStream = *req.MaxPerStream
+	}
+
+	if req.IsMaxPerUserPerStreamEnabled != nil {
+		reward.MaxPerUserPerStreamSetting.IsEnabled = *req.IsMaxPerUserPerStreamEnabled
+	}
+
+	if req.MaxPerUserPerStream != nil {
+		reward.MaxPerUserPerStreamSetting.MaxPerUserPerStream = *req.MaxPerUserPerStream
+	}
+
+	if req.IsGlobalCooldownEnabled != nil {
+		reward.GlobalCooldownSetting.IsEnabled = *req.IsGlobalCooldownEnabled
+	}
+
+	if req.GlobalCooldownSeconds != nil {
+		reward.GlobalCooldownSetting.GlobalCooldownSeconds = *req.GlobalCooldownSeconds
+	}
+
+	if req.IsPaused != nil {
+		reward.IsPaused = *req.IsPaused
+	}
+
+	if req.ShouldRedemptionsSkipRequestQueue != nil {
+		reward.ShouldRedemptionsSkipRequestQueue = *req.ShouldRedemptionsSkipRequestQueue
+	}
+
+	return nil
+}
+
+// getCustomRewards serves GetCustomRewards.
+func (s *Server) getCustomRewards(c *call) (any, error) {
+	broadcasterID, err := c.required("broadcaster_id")
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.owner(broadcasterID, "broadcaster_id")
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := c.capped("id", maxRewards)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every reward of the server was created by the client ID it serves,
+	// so only_manageable_rewards filters nothing.
+	rewards := filter(s.rewardsOf(broadcasterID), func(r twitchhelix.Reward) bool {
+		return len(ids) == 0 || slices.Contains(ids, r.ID)
+	})
+
+	return twitchhelix.ResponseCustomReward{Data: nonNil(rewards)}, nil
+}
+
+// rewardsOf returns the custom rewards of broadcasterID.
+func (s *Server) rewardsOf(broadcasterID string) []twitchhelix.Reward {
+	return filter(s.rewards, func(r twitchhelix.Reward) bool { return r.BroadcasterID == broadcasterID })
+}
+
+// createPoll serves CreatePoll.
+func (s *Server) createPoll(c *call) (any, error) {
+	var req twitchhelix.RequestCreatePoll
+
+	err := c.decode(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.BroadcasterID == "" {
+		return nil, errorf(http.StatusBadRequest, "Missing required parameter \"broadcaster_id\"")
+	}
+
+	err = c.owner(req.BroadcasterID, "broadcaster_id")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case req.Title == "" || utf8.RuneCountInString(req.Title) > maxPollTitle:
+		return nil, errorf(http.StatusBadRequest, "The title must be between 1 and %d characters long", maxPollTitle)
+	case len(req.Choices) < minPollChoices || len(req.Choices) > maxPollChoices:
+		return nil, errorf(http.StatusBadRequest, "A poll must have between %d and %d choices", minPollChoices, maxPollChoices)
+	case req.DurationInSeconds < minPollDuration || req.DurationInSeconds > maxPollDuration:
+		return nil, errorf(http.StatusBadRequest, "The duration must be between %d and %d seconds", minPollDuration, maxPollDuration)
+	case req.ChannelPointsVotingEnabled && req.ChannelPointsPerVote < 1:
+		return nil, errorf(http.StatusBadRequest, "The channel points per vote must be at least 1")
+	}
+
+	choices := make([]twitchhelix.Choice, 0, len(req.Choices))
+
+	for _, choice := range req.Choices {
+		if choice.Title == "" || utf8.RuneCountInString(choice.Title) > maxPollChoiceTitle {
+			return nil, errorf(http.StatusBadRequest, "Choice titles must be between 1 and %d characters long", maxPollChoiceTitle)
+		}
+
+		choices = append(choices, twitchhelix.Choice{ID: newID(), Title: choice.Title})
+	}
+
+	broadcaster, _ := s.user(req.BroadcasterID)
+
+	poll := twitchhelix.ResponseCreatePoll{
+		ID:                         newID(),
+		BroadcasterID:              req.BroadcasterID,
+		BroadcasterName:            broadcaster.DisplayName,
+		BroadcasterLogin:           broadcaster.Login,
+		Title:                      req.Title,
+		Choices:                    choices,
+		ChannelPointsVotingEnabled: req.ChannelPointsVotingEnabled,
+		ChannelPointsPerVote:       req.ChannelPointsPerVote,
+		Status:                     "ACTIVE",
+		Duration:                   req.DurationInSeconds,
+		StartedAt:                  time.Now().UTC(),
+	}
+
+	s.polls = append(s.polls, poll)
+
+	return data[twitchhelix.ResponseCreatePoll]{Data: []twitchhelix.ResponseCreatePoll{poll}}, nil
+}
+
+// startRaid serves StartRaid.
+func (s *Server) startRaid(c *call) (any, error) {
+	var req twitchhelix.RequestStartRaid
+
+	err := c.decode(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case req.FromBroadcasterID == "":
+		return nil, errorf(http.StatusBadRequest, "Missing required parameter \"from_broadcaster_id\"")
+	case req.ToBroadcasterID == "":
+		return nil, errorf(http.StatusBadRequest, "Missing required parameter \"to_broadcaster_id\"")
+	}
+
+	err = c.owner(req.FromBroadcasterID, "from_broadcaster_id")
+	if err != nil {
+		return nil, err
+	}
+
+	if req.FromBroadcasterID == req.ToBroadcasterID {
+		return nil, errorf(http.StatusBadRequest, "The broadcaster may not raid themselves")
+	}
+
+	if _, ok := s.user(req.ToBroadcasterID); !ok {
+		return nil, errorf(http.StatusNotFound, "The targeted channel was not found")
+	}
+
+	raid := Raid{
+		FromBroadcasterID: req.FromBroadcasterID,
+		ToBroadcasterID:   req.ToBroadcasterID,
+		CreatedAt:         time.Now(),
+	}
+
+	s.raids = append(s.raids, raid)
+
+	target, _ := s.stream(req.ToBroadcasterID)
+
+	return twitchhelix.ResponseStartRaid{StartRaidData: []twitchhelix.StartRaidData{{
+		CreatedAt: formatTime(raid.CreatedAt),
+		IsMature:  target.IsMature,
+	}}}, nil
+}
+
+// makeClip serves MakeClip.
+func (s *Server) makeClip(c *call) (any, error) {
+	broadcasterID, err := c.required("broadcaster_id")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, live := s.stream(broadcasterID); !live {
+		return nil, errorf(http.StatusNotFound, "The broadcaster is not live")
+	}
+
+	clip := Clip{
+		ID:            newID(),
+		BroadcasterID: broadcasterID,
+		CreatorID:     c.token.UserID,
+		CreatedAt:     time.Now(),
+	}
+
+	s.clips = append(s.clips, clip)
+
+	return data[twitchhelix.MakeClipResponse]{Data: []twitchhelix.MakeClipResponse{{
+		ID:      clip.ID,
+		EditURL: "https://clips.twitch.tv/" + clip.ID + "/edit",
+	}}}, nil
+}
@@ -0,0 +1,238 @@
+package helixtest
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	twitchhelix "github.com/v0idzzy/twitch-helix"
+)
+
+// maxEventSubCost is the total cost of the WebSocket subscriptions a user
+// may have, the one of Twitch.
+const maxEventSubCost = 10
+
+// EventSubSubscription is an EventSub subscription created with
+// CreateEventSubSubscription or one of the Event methods.
+type EventSubSubscription struct {
+	// ID is the ID of the subscription.
+	ID string
+
+	// Type is the subscription type, such as "stream.online".
+	Type string
+
+	// Version is the version of the subscription type.
+	Version string
+
+	// Condition maps the fields of the condition to their value. Fields
+	// sent as null are left out.
+	Condition map[string]string
+
+	// SessionID is the ID of the WebSocket session events are delivered
+	// to.
+	SessionID string
+
+	// UserID is the ID of the user whose token created the subscription.
+	UserID string
+
+	// Cost is how much the subscription counts against the cost limit.
+	Cost int
+
+	// CreatedAt is when the subscription was created.
+	CreatedAt time.Time
+}
+
+// eventSubType is an EventSub subscription type known to a Server.
+type eventSubType struct {
+	// version is the supported version of the type.
+	version string
+
+	// access is the token subscribing to the type needs.
+	access access
+}
+
+// eventSubTypes maps the subscription types a Server knows to their
+// requirements, written from the Twitch EventSub reference. Other types
+// are accepted with any version and without checking scopes.
+var eventSubTypes = map[string]eventSubType{
+	"channel.chat.message": {version: "1", access: access{scopes: []string{"user:read:chat"}}},
+	"stream.online":        {version: "1"},
+	"stream.offline":       {version: "1"},
+	"channel.update":       {version: "2"},
+	"channel.raid":         {version: "1"},
+	"channel.channel_points_custom_reward_redemption.add": {
+		version: "1",
+		access:  access{anyOf: []string{"channel:read:redemptions", "channel:manage:redemptions"}},
+	},
+	"channel.ad_break.begin":    {version: "1", access: access{scopes: []string{"channel:read:ads"}}},
+	"channel.subscription.gift": {version: "1", access: access{scopes: []string{"channel:read:subscriptions"}}},
+}
+
+// EventSubSubscriptions returns the EventSub subscriptions in order of
+// creation.
+func (s *Server) EventSubSubscriptions() []EventSubSubscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subs := slices.Clone(s.eventSubSubscriptions)
+	for i := range subs {
+		subs[i].Condition = maps.Clone(subs[i].Condition)
+	}
+
+	return subs
+}
+
+// createEventSubSubscription serves CreateEventSubSubscription and the
+// Event methods.
+//
+// Only the WebSocket transport is supported. The session ID is not checked
+// against a running session, and conditions are stored as sent.
+func (s *Server) createEventSubSubscription(c *call) (any, error) {
+	var req struct {
+		Type      string         `json:"type"`
+		Version   string         `json:"version"`
+		Condition map[string]any `json:"condition"`
+		Transport struct {
+			Method    string `json:"method"`
+			SessionID string `json:"session_id"`
+		} `json:"transport"`
+	}
+
+	err := c.decode(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Type == "" || req.Version == "" {
+		return nil, errorf(http.StatusBadRequest, "The type and version fields are required")
+	}
+
+	if t, ok := eventSubTypes[req.Type]; ok {
+		if req.Version != t.version {
+			return nil, errorf(http.StatusBadRequest, "The version %q of %s is not supported", req.Version, req.Type)
+		}
+
+		if t.access.check(c.token) != nil {
+			return nil, errorf(http.StatusForbidden, "subscription missing proper authorization")
+		}
+	}
+
+	if req.Transport.Method != "websocket" {
+		return nil, errorf(http.StatusBadRequest, "The transport method %q is not supported", req.Transport.Method)
+	}
+
+	if req.Transport.SessionID == "" {
+		return nil, errorf(http.StatusBadRequest, "The transport.session_id field is required")
+	}
+
+	condition := make(map[string]string)
+
+	for field, value := range req.Condition {
+		switch value := value.(type) {
+		case string:
+			condition[field] = value
+		case nil:
+		default:
+			return nil, errorf(http.StatusBadRequest, "The condition field %q must be a string", field)
+		}
+	}
+
+	if len(condition) == 0 {
+		return nil, errorf(http.StatusBadRequest, "The condition field is required")
+	}
+
+	sub := EventSubSubscription{
+		ID:        newID(),
+		Type:      req.Type,
+		Version:   req.Version,
+		Condition: condition,
+		SessionID: req.Transport.SessionID,
+		UserID:    c.token.UserID,
+		Cost:      eventSubCost(req.Type, condition, c.token.UserID),
+		CreatedAt: time.Now(),
+	}
+
+	total, totalCost := 0, 0
+
+	for _, existing := range s.eventSubSubscriptions {
+		if existing.UserID != sub.UserID {
+			continue
+		}
+
+		if existing.Type == sub.Type && existing.Version == sub.Version && existing.SessionID == sub.SessionID && maps.Equal(existing.Condition, sub.Condition) {
+			return nil, errorf(http.StatusConflict, "subscription already exists")
+		}
+
+		total++
+		totalCost += existing.Cost
+	}
+
+	if totalCost+sub.Cost > maxEventSubCost {
+		return nil, errorf(http.StatusTooManyRequests, "websocket transport cost exceeded")
+	}
+
+	s.eventSubSubscriptions = append(s.eventSubSubscriptions, sub)
+
+	return twitchhelix.EventSubSubscriptionResponse{
+		Data:         []twitchhelix.EventSubSubscription{eventSubSubscriptionData(sub)},
+		Total:        total + 1,
+		TotalCost:    totalCost + sub.Cost,
+		MaxTotalCost: maxEventSubCost,
+	}, nil
+}
+
+// deleteEventSubSubscription serves DeleteEventSubSubscription. Only the
+// user who created a subscription can delete it.
+func (s *Server) deleteEventSubSubscription(c *call) (any, error) {
+	id, err := c.required("id")
+	if err != nil {
+		return nil, err
+	}
+
+	i := slices.IndexFunc(s.eventSubSubscriptions, func(sub EventSubSubscription) bool {
+		return sub.ID == id && sub.UserID == c.token.UserID
+	})
+	if i < 0 {
+		return nil, errorf(http.StatusNotFound, "subscription not found")
+	}
+
+	s.eventSubSubscriptions = slices.Delete(s.eventSubSubscriptions, i, i+1)
+
+	return nil, nil
+}
+
+// eventSubCost returns the cost of a subscription of userID. Like on
+// Twitch, subscriptions needing the authorization of the user and ones
+// about the user themselves are free.
+func eventSubCost(subType string, condition map[string]string, userID string) int {
+	if t, ok := eventSubTypes[subType]; ok && (len(t.access.scopes) > 0 || len(t.access.anyOf) > 0) {
+		return 0
+	}
+
+	for _, field := range []string{"broadcaster_user_id", "user_id", "from_broadcaster_user_id", "to_broadcaster_user_id"} {
+		if condition[field] == userID {
+			return 0
+		}
+	}
+
+	return 1
+}
+
+// eventSubSubscriptionData returns sub as it appears in responses.
+func eventSubSubscriptionData(sub EventSubSubscription) twitchhelix.EventSubSubscription {
+	return twitchhelix.EventSubSubscription{
+		ID:        sub.ID,
+		Status:    "enabled",
+		Type:      sub.Type,
+		Version:   sub.Version,
+		Condition: maps.Clone(sub.Condition),
+		CreatedAt: sub.CreatedAt.UTC(),
+		Transport: twitchhelix.WebsocketTransport{
+			Method:      "websocket",
+			SessionID:   sub.SessionID,
+			ConnectedAt: ptr(sub.CreatedAt.UTC()),
+		},
+		Cost: sub.Cost,
+	}
+}
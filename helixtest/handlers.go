@@ -0,0 +1,471 @@
+package helixtest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	twitchhelix "github.com/v0idzzy/twitch-helix"
+)
+
+// maxIDs is the number of IDs most endpoints accept in one request.
+const maxIDs = 100
+
+// shoutout cooldowns enforced by SendShoutout.
+const (
+	// shoutoutCooldown is how long a broadcaster has to wait between
+	// shoutouts.
+	shoutoutCooldown = 2 * time.Minute
+
+	// shoutoutTargetCooldown is how long a broadcaster has to wait before
+	// giving the same broadcaster another shoutout.
+	shoutoutTargetCooldown = time.Hour
+)
+
+// apiError is an error response of the fake API.
+type apiError struct {
+	// status is the HTTP status code.
+	status int
+
+	// message describes the error.
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+// errorf returns an apiError with status and a formatted message.
+func errorf(status int, format string, args ...any) *apiError {
+	return &apiError{status: status, message: fmt.Sprintf(format, args...)}
+}
+
+// call is a Helix request being served.
+type call struct {
+	// request is the HTTP request.
+	request *http.Request
+
+	// query is the parsed query string.
+	query url.Values
+
+	// token describes the access token of the request.
+	token Token
+}
+
+// route is a Helix endpoint served by a Server.
+type route struct {
+	// method is the HTTP method of the endpoint.
+	method string
+
+	// path is the path of the endpoint below /helix/.
+	path string
+
+	// access is the token the endpoint accepts.
+	access access
+
+	// status is the status code of successful responses.
+	status int
+
+	// serve handles the call with s.mu held and returns the response body,
+	// nil for none.
+	serve func(s *Server, c *call) (any, error)
+}
+
+// access describes the token an endpoint accepts.
+//
+// It is written from the Twitch API reference rather than taken from the
+// requirements the client checks itself against, so a wrong requirement in
+// the client fails against the fake like it would against Twitch.
+type access struct {
+	// user reports whether a user access token is required.
+	user bool
+
+	// scopes are the scopes a user access token must have.
+	scopes []string
+
+	// anyOf are scopes of which a user access token must have one.
+	anyOf []string
+}
+
+// routes are the endpoints served by a Server.
+var routes = []route{
+	{method: "GET", path: "users", status: http.StatusOK, serve: (*Server).getUsers},
+	{method: "GET", path: "streams", status: http.StatusOK, serve: (*Server).getStreams},
+	{method: "GET", path: "search/channels", status: http.StatusOK, serve: (*Server).searchChannels},
+	{
+		method: "PATCH", path: "channels", status: http.StatusNoContent, serve: (*Server).modifyChannelInformation,
+		access: access{user: true, scopes: []string{"channel:manage:broadcast"}},
+	},
+	{
+		method: "GET", path: "chat/chatters", status: http.StatusOK, serve: (*Server).getChatters,
+		access: access{user: true, scopes: []string{"moderator:read:chatters"}},
+	},
+	{
+		method: "POST", path: "chat/messages", status: http.StatusOK, serve: (*Server).sendMessage,
+		access: access{scopes: []string{"user:write:chat"}},
+	},
+	{
+		method: "POST", path: "chat/shoutouts", status: http.StatusNoContent, serve: (*Server).sendShoutout,
+		access: access{user: true, scopes: []string{"moderator:manage:shoutouts"}},
+	},
+	{
+		method: "GET", path: "subscriptions", status: http.StatusOK, serve: (*Server).getSubscriptions,
+		access: access{user: true, scopes: []string{"channel:read:subscriptions"}},
+	},
+	{
+		method: "GET", path: "subscriptions/user", status: http.StatusOK, serve: (*Server).checkSubscription,
+		access: access{user: true, scopes: []string{"user:read:subscriptions"}},
+	},
+	{
+		method: "POST", path: "channel_points/custom_rewards", status: http.StatusOK, serve: (*Server).createCustomReward,
+		access: access{user: true, scopes: []string{"channel:manage:redemptions"}},
+	},
+	{
+		method: "PATCH", path: "channel_points/custom_rewards", status: http.StatusOK, serve: (*Server).updateCustomReward,
+		access: access{user: true, scopes: []string{"channel:manage:redemptions"}},
+	},
+	{
+		method: "GET", path: "channel_points/custom_rewards", status: http.StatusOK, serve: (*Server).getCustomRewards,
+		access: access{user: true, anyOf: []string{"channel:read:redemptions", "channel:manage:redemptions"}},
+	},
+	{
+		method: "POST", path: "polls", status: http.StatusOK, serve: (*Server).createPoll,
+		access: access{user: true, scopes: []string{"channel:manage:polls"}},
+	},
+	{
+		method: "POST", path: "raids", status: http.StatusOK, serve: (*Server).startRaid,
+		access: access{user: true, scopes: []string{"channel:manage:raids"}},
+	},
+	{
+		method: "POST", path: "clips", status: http.StatusAccepted, serve: (*Server).makeClip,
+		access: access{user: true, scopes: []string{"clips:edit"}},
+	},
+	{
+		method: "POST", path: "eventsub/subscriptions", status: http.StatusAccepted, serve: (*Server).createEventSubSubscription,
+		access: access{user: true},
+	},
+	{method: "DELETE", path: "eventsub/subscriptions", status: http.StatusNoContent, serve: (*Server).deleteEventSubSubscription},
+}
+
+// serveHelix serves the Helix API.
+func (s *Server) serveHelix(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/helix/")
+
+	i := slices.IndexFunc(routes, func(rt route) bool { return rt.method == r.Method && rt.path == path })
+	if i < 0 {
+		writeError(w, errorf(http.StatusNotFound, "Not Found"))
+
+		return
+	}
+
+	rt := routes[i]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, err := s.authorize(r)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	if !s.take(r.Header.Get("Authorization"), w.Header()) {
+		writeError(w, errorf(http.StatusTooManyRequests, "Too Many Requests"))
+
+		return
+	}
+
+	err = rt.access.check(token)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	body, err := rt.serve(s, &call{request: r, query: r.URL.Query(), token: token})
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	if body == nil {
+		w.WriteHeader(rt.status)
+
+		return
+	}
+
+	writeJSON(w, rt.status, body)
+}
+
+// serveValidate serves the OAuth validate endpoint.
+func (s *Server) serveValidate(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[strings.TrimPrefix(r.Header.Get("Authorization"), "OAuth ")]
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]any{
+			"status":  http.StatusUnauthorized,
+			"message": "invalid access token",
+		})
+
+		return
+	}
+
+	validation := twitchhelix.ValidateTokenResponse{
+		ClientID:  ClientID,
+		UserID:    token.UserID,
+		Scopes:    token.Scopes,
+		ExpiresIn: token.ExpiresIn,
+	}
+
+	if validation.Scopes == nil {
+		validation.Scopes = []string{}
+	}
+
+	if validation.ExpiresIn == 0 {
+		validation.ExpiresIn = 3600
+	}
+
+	if user, ok := s.user(token.UserID); ok {
+		validation.Login = user.Login
+	}
+
+	writeJSON(w, http.StatusOK, validation)
+}
+
+// authorize returns the token r is authorized with.
+func (s *Server) authorize(r *http.Request) (Token, error) {
+	value, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || value == "" {
+		return Token{}, errorf(http.StatusUnauthorized, "OAuth token is missing")
+	}
+
+	token, ok := s.tokens[value]
+	if !ok {
+		return Token{}, errorf(http.StatusUnauthorized, "Invalid OAuth token")
+	}
+
+	if r.Header.Get("Client-Id") != ClientID {
+		return Token{}, errorf(http.StatusUnauthorized, "Client ID and OAuth token do not match")
+	}
+
+	return token, nil
+}
+
+// take takes a request from the rate limit bucket of the token authorizing
+// it and reports the bucket in header. It reports false if the bucket is
+// empty.
+func (s *Server) take(authorization string, header http.Header) bool {
+	now := time.Now()
+
+	b, ok := s.buckets[authorization]
+	if !ok || !now.Before(b.reset) {
+		b = &bucket{remaining: s.rateLimit, reset: now.Add(s.rateLimitWindow)}
+		s.buckets[authorization] = b
+	}
+
+	allowed := b.remaining > 0
+	if allowed {
+		b.remaining--
+	}
+
+	header.Set("Ratelimit-Limit", strconv.Itoa(s.rateLimit))
+	header.Set("Ratelimit-Remaining", strconv.Itoa(b.remaining))
+	header.Set("Ratelimit-Reset", strconv.FormatInt(b.reset.Unix(), 10))
+
+	return allowed
+}
+
+// check returns an error if token is not accepted.
+func (a access) check(token Token) error {
+	if a.user && token.UserID == "" {
+		return errorf(http.StatusUnauthorized, "Missing User OAUTH Token")
+	}
+
+	// App access tokens carry no scopes.
+	if token.UserID == "" {
+		return nil
+	}
+
+	for _, scope := range a.scopes {
+		if !slices.Contains(token.Scopes, scope) {
+			return errorf(http.StatusUnauthorized, "Missing scope: %s", scope)
+		}
+	}
+
+	if len(a.anyOf) > 0 && !slices.ContainsFunc(a.anyOf, func(scope string) bool { return slices.Contains(token.Scopes, scope) }) {
+		return errorf(http.StatusUnauthorized, "Missing scope: %s", a.anyOf[0])
+	}
+
+	return nil
+}
+
+// required returns the value of the required query parameter name.
+func (c *call) required(name string) (string, error) {
+	value := c.query.Get(name)
+	if value == "" {
+		return "", errorf(http.StatusBadRequest, "Missing required parameter %q", name)
+	}
+
+	return value, nil
+}
+
+// capped returns the values of the query parameter name, of which there
+// may be at most limit.
+func (c *call) capped(name string, limit int) ([]string, error) {
+	values := c.query[name]
+	if len(values) > limit {
+		return nil, errorf(http.StatusBadRequest, "The parameter %q may not be specified more than %d times", name, limit)
+	}
+
+	return values, nil
+}
+
+// owner returns an error if the token of c does not belong to userID,
+// passed as the query or body parameter param.
+func (c *call) owner(userID, param string) error {
+	if c.token.UserID != userID {
+		return errorf(http.StatusUnauthorized, "The ID in %s must match the user ID in the access token", param)
+	}
+
+	return nil
+}
+
+// decode decodes the JSON request body into v.
+func (c *call) decode(v any) error {
+	err := json.NewDecoder(c.request.Body).Decode(v)
+	if err != nil {
+		return errorf(http.StatusBadRequest, "Malformed request body: %v", err)
+	}
+
+	return nil
+}
+
+// pageCursor is the content of a pagination cursor.
+type pageCursor struct {
+	// Offset is the index of the item the cursor points at.
+	Offset int `json:"o"`
+}
+
+// page returns the range of the total items to return, according to the
+// first, after and before query parameters, and the cursor to the next
+// page in the same direction, empty if there is none.
+//
+// first defaults to def and may not exceed limit.
+func (c *call) page(total, def, limit int) (start, end int, cursor string, err error) {
+	first := def
+
+	if value := c.query.Get("first"); value != "" {
+		first, err = strconv.Atoi(value)
+		if err != nil || first < 1 || first > limit {
+			return 0, 0, "", errorf(http.StatusBadRequest, "The parameter \"first\" must be between 1 and %d", limit)
+		}
+	}
+
+	if before := c.query.Get("before"); before != "" && c.query.Get("after") == "" {
+		end, err = decodeCursor(before)
+		if err != nil {
+			return 0, 0, "", err
+		}
+
+		end = min(end, total)
+		start = max(end-first, 0)
+
+		if start > 0 {
+			cursor = encodeCursor(start)
+		}
+
+		return start, end, cursor, nil
+	}
+
+	if after := c.query.Get("after"); after != "" {
+		start, err = decodeCursor(after)
+		if err != nil {
+			return 0, 0, "", err
+		}
+	}
+
+	start = min(start, total)
+	end = min(start+first, total)
+
+	if end < total {
+		cursor = encodeCursor(end)
+	}
+
+	return start, end, cursor, nil
+}
+
+// encodeCursor returns the cursor pointing at offset.
+func encodeCursor(offset int) string {
+	data, _ := json.Marshal(pageCursor{Offset: offset})
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor returns the offset cursor points at.
+func decodeCursor(cursor string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+
+	var decoded pageCursor
+
+	if err != nil || json.Unmarshal(data, &decoded) != nil || decoded.Offset < 0 {
+		return 0, errorf(http.StatusBadRequest, "Invalid cursor")
+	}
+
+	return decoded.Offset, nil
+}
+
+// writeJSON writes v as the JSON response body with status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes err as a Helix error response.
+func writeError(w http.ResponseWriter, err error) {
+	apiErr, ok := err.(*apiError)
+	if !ok {
+		apiErr = errorf(http.StatusInternalServerError, "%v", err)
+	}
+
+	writeJSON(w, apiErr.status, map[string]any{
+		"error":   http.StatusText(apiErr.status),
+		"status":  apiErr.status,
+		"message": apiErr.message,
+	})
+}
+
+// data is a response carrying its items in a data array.
+type data[T any] struct {
+	Data []T `json:"data"`
+}
+
+// nonNil returns items, or an empty slice if items is nil, so it encodes
+// as an empty JSON array.
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+
+	return items
+}
+
+// ptr returns a pointer to v.
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// formatTime formats t like Twitch timestamps.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,371 @@
+package helixtest
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ClientSecret is the client secret a Server accepts with ClientID.
+const ClientSecret = "helixtest-client-secret"
+
+// Device authorization grant parameters of a Server.
+const (
+	// deviceCodeGrantType is the grant type of the device authorization
+	// grant.
+	deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+	// deviceCodeExpiresIn is the number of seconds a device code is valid
+	// for.
+	deviceCodeExpiresIn = 1800
+
+	// devicePollInterval is the number of seconds clients are asked to wait
+	// between token polls. Twitch asks for 5; the fake keeps tests fast.
+	devicePollInterval = 1
+)
+
+// authorizationCodeExpiresIn is the number of seconds an authorization code
+// is valid for.
+const authorizationCodeExpiresIn = 600
+
+// tokenExpiresIn is the number of seconds tokens issued by a Server are
+// valid for.
+const tokenExpiresIn = 3600
+
+// device is a pending device authorization.
+type device struct {
+	// userCode is the code the user enters to authorize the device.
+	userCode string
+
+	// scopes are the scopes requested for the device.
+	scopes []string
+
+	// expiry is when the device code expires.
+	expiry time.Time
+
+	// userID is the ID of the user who authorized the device, empty while
+	// it is not authorized.
+	userID string
+}
+
+// authorization is an authorization code issued by the authorize endpoint.
+type authorization struct {
+	// userID is the ID of the user who authorized the client.
+	userID string
+
+	// scopes are the scopes the user granted.
+	scopes []string
+
+	// redirectURI is the redirect URI the code was issued for, which the
+	// token request must be sent with too.
+	redirectURI string
+
+	// expiry is when the code expires.
+	expiry time.Time
+}
+
+// tokenResponse is the response of the token endpoint.
+type tokenResponse struct {
+	AccessToken  string   `json:"access_token"`
+	RefreshToken string   `json:"refresh_token,omitempty"`
+	ExpiresIn    int      `json:"expires_in"`
+	Scope        []string `json:"scope,omitempty"`
+	TokenType    string   `json:"token_type"`
+}
+
+// AuthorizeDevice authorizes the device that requested userCode on behalf
+// of userID, as the user would on the verification page. The next poll of
+// the device gets a user access token with the requested scopes.
+//
+// It reports false if no pending device requested userCode.
+func (s *Server) AuthorizeDevice(userCode, userID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, d := range s.devices {
+		if d.userCode == userCode && time.Now().Before(d.expiry) {
+			d.userID = userID
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetAuthorizeUser makes the authorize endpoint approve authorizations on
+// behalf of userID, as the user would by clicking Authorize. An empty
+// userID, the default, makes the user deny them.
+func (s *Server) SetAuthorizeUser(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.authorizeUserID = userID
+}
+
+// serveAuthorize serves the OAuth authorize page of the authorization code
+// flow. It redirects the user back right away, with a code if the user set
+// with SetAuthorizeUser approves and with an access_denied error otherwise.
+func (s *Server) serveAuthorize(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+
+	if query.Get("client_id") != ClientID {
+		writeOAuthError(w, http.StatusBadRequest, "invalid client")
+
+		return
+	}
+
+	if query.Get("response_type") != "code" {
+		writeOAuthError(w, http.StatusBadRequest, "unsupported response type")
+
+		return
+	}
+
+	redirectURI := query.Get("redirect_uri")
+
+	redirect, err := url.Parse(redirectURI)
+	if redirectURI == "" || err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid redirect uri")
+
+		return
+	}
+
+	params := url.Values{}
+
+	if s.authorizeUserID == "" {
+		params.Set("error", "access_denied")
+		params.Set("error_description", "The user denied you access")
+	} else {
+		code := newToken()
+
+		s.codes[code] = &authorization{
+			userID:      s.authorizeUserID,
+			scopes:      strings.Fields(query.Get("scope")),
+			redirectURI: redirectURI,
+			expiry:      time.Now().Add(authorizationCodeExpiresIn * time.Second),
+		}
+
+		params.Set("code", code)
+		params.Set("scope", query.Get("scope"))
+	}
+
+	if state := query.Get("state"); state != "" {
+		params.Set("state", state)
+	}
+
+	redirect.RawQuery = params.Encode()
+
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+// serveToken serves the OAuth token endpoint, which exchanges refresh
+// tokens, client credentials, authorization codes and authorized device
+// codes for tokens.
+func (s *Server) serveToken(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.PostFormValue("client_id") != ClientID {
+		writeOAuthError(w, http.StatusBadRequest, "invalid client")
+
+		return
+	}
+
+	switch r.PostFormValue("grant_type") {
+	case "refresh_token":
+		s.refreshToken(w, r)
+	case "client_credentials":
+		s.clientCredentials(w, r)
+	case "authorization_code":
+		s.authorizationCode(w, r)
+	case deviceCodeGrantType:
+		s.deviceToken(w, r)
+	default:
+		writeOAuthError(w, http.StatusBadRequest, "unsupported grant type")
+	}
+}
+
+// refreshToken serves a refresh token grant. Like Twitch, the refresh token
+// is rotated and the old access token stops being accepted.
+func (s *Server) refreshToken(w http.ResponseWriter, r *http.Request) {
+	if r.PostFormValue("client_secret") != ClientSecret {
+		writeOAuthError(w, http.StatusForbidden, "invalid client secret")
+
+		return
+	}
+
+	refreshToken := r.PostFormValue("refresh_token")
+
+	for accessToken, token := range s.tokens {
+		if refreshToken == "" || token.RefreshToken != refreshToken {
+			continue
+		}
+
+		delete(s.tokens, accessToken)
+
+		writeJSON(w, http.StatusOK, s.issue(token.UserID, token.Scopes))
+
+		return
+	}
+
+	writeOAuthError(w, http.StatusBadRequest, "Invalid refresh token")
+}
+
+// clientCredentials serves a client credentials grant.
+func (s *Server) clientCredentials(w http.ResponseWriter, r *http.Request) {
+	if r.PostFormValue("client_secret") != ClientSecret {
+		writeOAuthError(w, http.StatusForbidden, "invalid client secret")
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.issue("", nil))
+}
+
+// authorizationCode serves an authorization code grant. Codes can be
+// exchanged once, with the redirect URI they were issued for.
+func (s *Server) authorizationCode(w http.ResponseWriter, r *http.Request) {
+	if r.PostFormValue("client_secret") != ClientSecret {
+		writeOAuthError(w, http.StatusForbidden, "invalid client secret")
+
+		return
+	}
+
+	code := r.PostFormValue("code")
+
+	a, ok := s.codes[code]
+	delete(s.codes, code)
+
+	if !ok || time.Now().After(a.expiry) || r.PostFormValue("redirect_uri") != a.redirectURI {
+		writeOAuthError(w, http.StatusBadRequest, "Invalid authorization code")
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.issue(a.userID, a.scopes))
+}
+
+// deviceToken serves a device code grant.
+func (s *Server) deviceToken(w http.ResponseWriter, r *http.Request) {
+	deviceCode := r.PostFormValue("device_code")
+
+	d, ok := s.devices[deviceCode]
+	if !ok || time.Now().After(d.expiry) {
+		delete(s.devices, deviceCode)
+		writeOAuthError(w, http.StatusBadRequest, "invalid device code")
+
+		return
+	}
+
+	if d.userID == "" {
+		writeOAuthError(w, http.StatusBadRequest, "authorization_pending")
+
+		return
+	}
+
+	delete(s.devices, deviceCode)
+
+	writeJSON(w, http.StatusOK, s.issue(d.userID, d.scopes))
+}
+
+// serveDevice serves the OAuth device authorization endpoint. The device is
+// authorized with AuthorizeDevice.
+func (s *Server) serveDevice(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.PostFormValue("client_id") != ClientID {
+		writeOAuthError(w, http.StatusBadRequest, "invalid client")
+
+		return
+	}
+
+	deviceCode := newID()
+	userCode := strings.ToUpper(strings.ReplaceAll(newID()[:9], "-", ""))
+
+	s.devices[deviceCode] = &device{
+		userCode: userCode,
+		scopes:   strings.Fields(r.PostFormValue("scopes")),
+		expiry:   time.Now().Add(deviceCodeExpiresIn * time.Second),
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"device_code":      deviceCode,
+		"expires_in":       deviceCodeExpiresIn,
+		"interval":         devicePollInterval,
+		"user_code":        userCode,
+		"verification_uri": "https://www.twitch.tv/activate?public=true&device-code=" + userCode,
+	})
+}
+
+// serveRevoke serves the OAuth revoke endpoint. Both access and refresh
+// tokens can be revoked, which stops the token from being accepted.
+func (s *Server) serveRevoke(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.PostFormValue("client_id") != ClientID {
+		writeOAuthError(w, http.StatusBadRequest, "invalid client")
+
+		return
+	}
+
+	revoked := r.PostFormValue("token")
+
+	for accessToken, token := range s.tokens {
+		if revoked != "" && (accessToken == revoked || token.RefreshToken == revoked) {
+			delete(s.tokens, accessToken)
+			w.WriteHeader(http.StatusOK)
+
+			return
+		}
+	}
+
+	writeOAuthError(w, http.StatusBadRequest, "Invalid token")
+}
+
+// issue adds a new token of userID with scopes and returns it as a token
+// endpoint response. User access tokens come with a refresh token. s.mu
+// must be held.
+func (s *Server) issue(userID string, scopes []string) tokenResponse {
+	resp := tokenResponse{
+		AccessToken: newToken(),
+		ExpiresIn:   tokenExpiresIn,
+		Scope:       scopes,
+		TokenType:   "bearer",
+	}
+
+	if userID != "" {
+		resp.RefreshToken = newToken()
+
+		if resp.Scope == nil {
+			resp.Scope = []string{}
+		}
+	}
+
+	s.tokens[resp.AccessToken] = Token{
+		UserID:       userID,
+		Scopes:       scopes,
+		RefreshToken: resp.RefreshToken,
+		ExpiresIn:    tokenExpiresIn,
+	}
+
+	return resp
+}
+
+// newToken returns a random token formatted like a Twitch token.
+func newToken() string {
+	return strings.ReplaceAll(newID(), "-", "")[:30]
+}
+
+// writeOAuthError writes an OAuth error response with message.
+func writeOAuthError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{
+		"status":  status,
+		"message": message,
+	})
+}
@@ -0,0 +1,611 @@
+// Package helixtest provides an in-memory fake of the Twitch Helix API for
+// testing code built on twitchhelix without talking to Twitch.
+//
+// A Server keeps users, channels, streams, chatters, subscriptions, custom
+// rewards, polls, chat messages and EventSub subscriptions as state. It
+// serves them on the paths of the real API, validating parameters, ID
+// limits and the scopes of the calling token, and answers with pagination
+// cursors and rate limit headers like Twitch does. Its OAuth endpoints
+// issue, refresh and revoke tokens, so refresh, client credentials,
+// authorization code and device flows work against it too:
+//
+//	server := helixtest.NewServer()
+//	defer server.Close()
+//
+//	server.AddUser(twitchhelix.User{ID: "1", Login: "streamer"})
+//	server.AddToken("token", helixtest.Token{UserID: "1", Scopes: []string{"clips:edit"}})
+//
+//	client := server.NewClient("token")
+package helixtest
+
+import (
+	"cmp"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	twitchhelix "github.com/v0idzzy/twitch-helix"
+)
+
+// ClientID is the client ID requests to a Server must be sent with.
+const ClientID = "helixtest-client-id"
+
+// Default rate limit of a Server, the one of Twitch.
+const (
+	defaultRateLimit       = 800
+	defaultRateLimitWindow = time.Minute
+)
+
+// Token describes an access token accepted by a Server.
+type Token struct {
+	// UserID is the ID of the user the token belongs to, empty for an app
+	// access token.
+	UserID string
+
+	// Scopes are the scopes granted to the token.
+	Scopes []string
+
+	// RefreshToken is the refresh token the OAuth token endpoint exchanges
+	// for a new token of the same user with the same scopes, empty if none.
+	RefreshToken string
+
+	// ExpiresIn is the number of seconds the token is reported valid for
+	// by the validate endpoint. Zero reports 3600.
+	ExpiresIn int
+}
+
+// Channel is the channel information of a broadcaster.
+type Channel struct {
+	// BroadcasterID is the ID of the broadcaster.
+	BroadcasterID string
+
+	// Language is the broadcast language, such as "en".
+	Language string
+
+	// GameID is the ID of the game being played.
+	GameID string
+
+	// GameName is the name of the game being played.
+	GameName string
+
+	// Title is the title of the stream.
+	Title string
+
+	// Delay is the stream delay in seconds.
+	Delay int
+
+	// Tags are the tags of the channel.
+	Tags []string
+
+	// IsBrandedContent reports whether the channel has branded content.
+	IsBrandedContent bool
+}
+
+// Stream is a live stream.
+type Stream struct {
+	// ID is the ID of the stream. Empty generates one.
+	ID string
+
+	// UserID is the ID of the broadcaster.
+	UserID string
+
+	// GameID is the ID of the game being played. Empty takes the one of
+	// the channel.
+	GameID string
+
+	// GameName is the name of the game being played. Empty takes the one
+	// of the channel.
+	GameName string
+
+	// Title is the title of the stream. Empty takes the one of the channel.
+	Title string
+
+	// Language is the broadcast language. Empty takes the one of the
+	// channel.
+	Language string
+
+	// ViewerCount is the number of viewers.
+	ViewerCount int
+
+	// StartedAt is when the stream started. Zero uses the current time.
+	StartedAt time.Time
+
+	// Tags are the tags of the stream. Nil takes the ones of the channel.
+	Tags []string
+
+	// IsMature reports whether the stream is for mature audiences.
+	IsMature bool
+}
+
+// Subscription is a user's subscription to a broadcaster.
+type Subscription struct {
+	// BroadcasterID is the ID of the broadcaster subscribed to.
+	BroadcasterID string
+
+	// UserID is the ID of the subscriber.
+	UserID string
+
+	// Tier is "1000", "2000" or "3000". Empty means "1000".
+	Tier string
+
+	// GifterID is the ID of the user who gifted the subscription, empty if
+	// it was not a gift.
+	GifterID string
+}
+
+// ChatMessage is a chat message sent with SendMessage.
+type ChatMessage struct {
+	// ID is the ID of the message.
+	ID string
+
+	// BroadcasterID is the ID of the channel the message was sent to.
+	BroadcasterID string
+
+	// SenderID is the ID of the user who sent the message.
+	SenderID string
+
+	// Message is the text of the message.
+	Message string
+
+	// ReplyParentMessageID is the ID of the message replied to, empty if
+	// none.
+	ReplyParentMessageID string
+
+	// SentAt is when the message was sent.
+	SentAt time.Time
+}
+
+// Shoutout is a shoutout sent with SendShoutout.
+type Shoutout struct {
+	// FromBroadcasterID is the ID of the broadcaster giving the shoutout.
+	FromBroadcasterID string
+
+	// ToBroadcasterID is the ID of the broadcaster receiving the shoutout.
+	ToBroadcasterID string
+
+	// ModeratorID is the ID of the user who sent the shoutout.
+	ModeratorID string
+
+	// SentAt is when the shoutout was sent.
+	SentAt time.Time
+}
+
+// Raid is a raid started with StartRaid.
+type Raid struct {
+	// FromBroadcasterID is the ID of the raiding broadcaster.
+	FromBroadcasterID string
+
+	// ToBroadcasterID is the ID of the raided broadcaster.
+	ToBroadcasterID string
+
+	// CreatedAt is when the raid was started.
+	CreatedAt time.Time
+}
+
+// Clip is a clip created with MakeClip.
+type Clip struct {
+	// ID is the ID of the clip.
+	ID string
+
+	// BroadcasterID is the ID of the clipped broadcaster.
+	BroadcasterID string
+
+	// CreatorID is the ID of the user who created the clip.
+	CreatorID string
+
+	// CreatedAt is when the clip was created.
+	CreatedAt time.Time
+}
+
+// Server is a fake Twitch Helix API.
+//
+// Its state can be changed and inspected while it serves requests. Server
+// is safe for concurrent use.
+type Server struct {
+	// server serves the API.
+	server *httptest.Server
+
+	// mu guards the fields below.
+	mu sync.Mutex
+
+	// tokens maps access tokens to their description.
+	tokens map[string]Token
+
+	// devices maps device codes to their pending device authorization.
+	devices map[string]*device
+
+	// codes maps authorization codes to the authorization they were issued
+	// for.
+	codes map[string]*authorization
+
+	// authorizeUserID is the ID of the user approving authorizations on
+	// the authorize endpoint, empty if authorizations are denied.
+	authorizeUserID string
+
+	// users are the users in order of creation.
+	users []twitchhelix.User
+
+	// channels maps broadcaster IDs to their channel.
+	channels map[string]*Channel
+
+	// streams are the live streams.
+	streams []Stream
+
+	// chatters maps broadcaster IDs to the IDs of users in their chat.
+	chatters map[string][]string
+
+	// moderators maps broadcaster IDs to the IDs of their moderators.
+	moderators map[string][]string
+
+	// subscriptions are the subscriptions in order of creation.
+	subscriptions []Subscription
+
+	// rewards are the custom rewards in order of creation.
+	rewards []twitchhelix.Reward
+
+	// polls are the polls in order of creation.
+	polls []twitchhelix.ResponseCreatePoll
+
+	// messages are the sent chat messages.
+	messages []ChatMessage
+
+	// shoutouts are the sent shoutouts.
+	shoutouts []Shoutout
+
+	// raids are the started raids.
+	raids []Raid
+
+	// clips are the created clips.
+	clips []Clip
+
+	// eventSubSubscriptions are the EventSub subscriptions in order of
+	// creation.
+	eventSubSubscriptions []EventSubSubscription
+
+	// rateLimit is the number of requests a token may send per
+	// rateLimitWindow.
+	rateLimit int
+
+	// rateLimitWindow is how often rate limit buckets refill.
+	rateLimitWindow time.Duration
+
+	// buckets maps access tokens to their rate limit bucket.
+	buckets map[string]*bucket
+}
+
+// bucket is the rate limit bucket of a token.
+type bucket struct {
+	// remaining is the number of requests left until reset.
+	remaining int
+
+	// reset is when the bucket refills.
+	reset time.Time
+}
+
+// NewServer starts and returns a Server without state. Call Close when
+// done.
+func NewServer() *Server {
+	s := &Server{
+		tokens:          make(map[string]Token),
+		devices:         make(map[string]*device),
+		codes:           make(map[string]*authorization),
+		channels:        make(map[string]*Channel),
+		chatters:        make(map[string][]string),
+		moderators:      make(map[string][]string),
+		rateLimit:       defaultRateLimit,
+		rateLimitWindow: defaultRateLimitWindow,
+		buckets:         make(map[string]*bucket),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/helix/", s.serveHelix)
+	mux.HandleFunc("GET /oauth2/validate", s.serveValidate)
+	mux.HandleFunc("GET /oauth2/authorize", s.serveAuthorize)
+	mux.HandleFunc("POST /oauth2/token", s.serveToken)
+	mux.HandleFunc("POST /oauth2/device", s.serveDevice)
+	mux.HandleFunc("POST /oauth2/revoke", s.serveRevoke)
+
+	s.server = httptest.NewServer(mux)
+
+	return s
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// URL returns the base URL of the Helix API of the server, to be passed to
+// twitchhelix.WithBaseURL.
+func (s *Server) URL() string {
+	return s.server.URL + "/helix/"
+}
+
+// AuthURL returns the base URL of the OAuth endpoints of the server, to be
+// passed to twitchhelix.WithAuthURL.
+//
+// The validate, authorize, token, device and revoke endpoints are served
+// from the tokens of the server. Token requests must be sent with ClientID
+// and ClientSecret, and issued tokens are accepted by the API right away.
+// Refreshing a token replaces it, authorizations are approved for the user
+// set with SetAuthorizeUser, and devices are authorized with
+// AuthorizeDevice.
+func (s *Server) AuthURL() string {
+	return s.server.URL + "/oauth2/"
+}
+
+// NewClient returns a Client sending requests to the server, authorized
+// with token. opts are applied after the options pointing the client at
+// the server.
+func (s *Server) NewClient(token string, opts ...twitchhelix.Option) *twitchhelix.Client {
+	clientID := ClientID
+
+	opts = append([]twitchhelix.Option{
+		twitchhelix.WithBaseURL(s.URL()),
+		twitchhelix.WithAuthURL(s.AuthURL()),
+		twitchhelix.WithHTTPClient(s.server.Client()),
+	}, opts...)
+
+	return twitchhelix.NewClient(&clientID, &token, nil, opts...)
+}
+
+// AddToken makes the server accept token as described by t.
+func (s *Server) AddToken(token string, t Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t.Scopes = slices.Clone(t.Scopes)
+	s.tokens[token] = t
+}
+
+// RevokeToken makes the server reject token.
+func (s *Server) RevokeToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tokens, token)
+}
+
+// SetRateLimit makes every token's bucket hold limit requests, refilled
+// every window. The default is 800 requests per minute.
+func (s *Server) SetRateLimit(limit int, window time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rateLimit = limit
+	s.rateLimitWindow = window
+	clear(s.buckets)
+}
+
+// AddUser adds user and an empty channel for them. A user with the same ID
+// is replaced. An empty CreatedAt is set to the current time.
+func (s *Server) AddUser(user twitchhelix.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user.CreatedAt == "" {
+		user.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	if user.DisplayName == "" {
+		user.DisplayName = user.Login
+	}
+
+	s.users = slices.DeleteFunc(s.users, func(u twitchhelix.User) bool { return u.ID == user.ID })
+	s.users = append(s.users, user)
+
+	if _, ok := s.channels[user.ID]; !ok {
+		s.channels[user.ID] = &Channel{BroadcasterID: user.ID, Language: "en"}
+	}
+}
+
+// SetChannel replaces the channel information of channel.BroadcasterID.
+func (s *Server) SetChannel(channel Channel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	channel.Tags = slices.Clone(channel.Tags)
+	s.channels[channel.BroadcasterID] = &channel
+}
+
+// Channel returns the channel information of broadcasterID.
+// ok is false if there is no such channel.
+func (s *Server) Channel(broadcasterID string) (channel Channel, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.channels[broadcasterID]
+	if !ok {
+		return Channel{}, false
+	}
+
+	channel = *c
+	channel.Tags = slices.Clone(c.Tags)
+
+	return channel, true
+}
+
+// StartStream makes stream.UserID live, replacing a stream they already
+// have. Empty fields are filled in from their channel.
+func (s *Server) StartStream(stream Stream) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stream.ID == "" {
+		stream.ID = newID()
+	}
+
+	if stream.StartedAt.IsZero() {
+		stream.StartedAt = time.Now()
+	}
+
+	if channel, ok := s.channels[stream.UserID]; ok {
+		stream.GameID = cmp.Or(stream.GameID, channel.GameID)
+		stream.GameName = cmp.Or(stream.GameName, channel.GameName)
+		stream.Title = cmp.Or(stream.Title, channel.Title)
+		stream.Language = cmp.Or(stream.Language, channel.Language)
+
+		if stream.Tags == nil {
+			stream.Tags = slices.Clone(channel.Tags)
+		}
+	}
+
+	s.streams = slices.DeleteFunc(s.streams, func(st Stream) bool { return st.UserID == stream.UserID })
+	s.streams = append(s.streams, stream)
+}
+
+// EndStream makes userID go offline.
+func (s *Server) EndStream(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.streams = slices.DeleteFunc(s.streams, func(st Stream) bool { return st.UserID == userID })
+}
+
+// AddChatter adds userID to the chat of broadcasterID.
+func (s *Server) AddChatter(broadcasterID, userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !slices.Contains(s.chatters[broadcasterID], userID) {
+		s.chatters[broadcasterID] = append(s.chatters[broadcasterID], userID)
+	}
+}
+
+// RemoveChatter removes userID from the chat of broadcasterID.
+func (s *Server) RemoveChatter(broadcasterID, userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.chatters[broadcasterID] = slices.DeleteFunc(s.chatters[broadcasterID], func(id string) bool { return id == userID })
+}
+
+// AddModerator makes userID a moderator of broadcasterID.
+func (s *Server) AddModerator(broadcasterID, userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !slices.Contains(s.moderators[broadcasterID], userID) {
+		s.moderators[broadcasterID] = append(s.moderators[broadcasterID], userID)
+	}
+}
+
+// AddSubscription adds sub, replacing an existing subscription of the user
+// to the broadcaster.
+func (s *Server) AddSubscription(sub Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sub.Tier == "" {
+		sub.Tier = "1000"
+	}
+
+	s.subscriptions = slices.DeleteFunc(s.subscriptions, func(existing Subscription) bool {
+		return existing.BroadcasterID == sub.BroadcasterID && existing.UserID == sub.UserID
+	})
+	s.subscriptions = append(s.subscriptions, sub)
+}
+
+// Rewards returns the custom rewards of broadcasterID.
+func (s *Server) Rewards(broadcasterID string) []twitchhelix.Reward {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return filter(s.rewards, func(r twitchhelix.Reward) bool { return r.BroadcasterID == broadcasterID })
+}
+
+// Polls returns the polls of broadcasterID.
+func (s *Server) Polls(broadcasterID string) []twitchhelix.ResponseCreatePoll {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return filter(s.polls, func(p twitchhelix.ResponseCreatePoll) bool { return p.BroadcasterID == broadcasterID })
+}
+
+// Messages returns the chat messages sent to broadcasterID.
+func (s *Server) Messages(broadcasterID string) []ChatMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return filter(s.messages, func(m ChatMessage) bool { return m.BroadcasterID == broadcasterID })
+}
+
+// Shoutouts returns the sent shoutouts.
+func (s *Server) Shoutouts() []Shoutout {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.shoutouts)
+}
+
+// Raids returns the started raids.
+func (s *Server) Raids() []Raid {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.raids)
+}
+
+// Clips returns the created clips.
+func (s *Server) Clips() []Clip {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.clips)
+}
+
+// user returns the user with the given ID. s.mu must be held.
+func (s *Server) user(id string) (twitchhelix.User, bool) {
+	i := slices.IndexFunc(s.users, func(u twitchhelix.User) bool { return u.ID == id })
+	if i < 0 {
+		return twitchhelix.User{}, false
+	}
+
+	return s.users[i], true
+}
+
+// userByLogin returns the user with the given login. s.mu must be held.
+func (s *Server) userByLogin(login string) (twitchhelix.User, bool) {
+	i := slices.IndexFunc(s.users, func(u twitchhelix.User) bool { return strings.EqualFold(u.Login, login) })
+	if i < 0 {
+		return twitchhelix.User{}, false
+	}
+
+	return s.users[i], true
+}
+
+// stream returns the live stream of userID. s.mu must be held.
+func (s *Server) stream(userID string) (Stream, bool) {
+	i := slices.IndexFunc(s.streams, func(st Stream) bool { return st.UserID == userID })
+	if i < 0 {
+		return Stream{}, false
+	}
+
+	return s.streams[i], true
+}
+
+// newID returns a random ID formatted like a UUID.
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// filter returns the items for which keep reports true.
+func filter[T any](items []T, keep func(T) bool) []T {
+	var kept []T
+
+	for _, item := range items {
+		if keep(item) {
+			kept = append(kept, item)
+		}
+	}
+
+	return kept
+}
@@ -17,14 +17,9 @@ type MakeClipResponse struct {
 // Twitch tries to capture the previous 90 seconds and provide the edit url
 // Twitch will only publish the last 30s of the clip by default
 //
-// If nothing is returned after 15 seconds, assume clip creation has failed
+// If nothing is returned after 15 seconds, assume clip creation has failed.
+//
+// Requires a user access token with the clips:edit scope.
 func (c *Client) MakeClip(ctx context.Context, broadcaster_id string) (*MakeClipResponse, error) {
-	var resp MakeClipResponse
-
-	err := c.doRequest(ctx, "POST", "clips?broadcaster_id="+broadcaster_id, nil, &resp)
-	if err != nil {
-		return nil, err
-	}
-
-	return &resp, nil
+	return doDataRequest[MakeClipResponse](ctx, c, "POST", "clips?broadcaster_id="+broadcaster_id, nil)
 }
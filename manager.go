@@ -0,0 +1,326 @@
+package twitchhelix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoAccount is returned by Manager.For when no account matches.
+var ErrNoAccount = errors.New("no account registered")
+
+// Manager holds the Clients of many Twitch accounts of one application,
+// such as a bot acting in the channels of several broadcasters.
+//
+// Every account has its own token, refreshed independently of the others.
+// All Clients share one HTTPClient and one RateLimiter, which tracks the
+// rate limit bucket of each token. An app access token Client is used for
+// calls no account is needed for.
+//
+// Manager is safe for concurrent use.
+type Manager struct {
+	// clientID is the Twitch application client ID.
+	clientID string
+
+	// clientSecret is the Twitch application client secret.
+	clientSecret string
+
+	// httpClient sends the requests of every Client.
+	httpClient HTTPClient
+
+	// opts configure every Client.
+	opts []Option
+
+	// rateLimiter is shared by every Client.
+	rateLimiter *RateLimiter
+
+	// app is the Client authorized with an app access token.
+	app *Client
+
+	// mu guards accounts.
+	mu sync.RWMutex
+
+	// accounts maps user IDs to their account.
+	accounts map[string]*account
+}
+
+// account is a Twitch account registered with a Manager.
+type account struct {
+	// client sends the requests of the account.
+	client *Client
+
+	// mu guards status.
+	mu sync.Mutex
+
+	// status is the authorization status of the account.
+	status AccountStatus
+}
+
+// AccountStatus describes whether the token of an account works.
+type AccountStatus struct {
+	// UserID is the ID of the account.
+	UserID string
+
+	// Err is the error that showed the account's authorization is broken,
+	// nil if it works.
+	Err error
+
+	// Since is when the account's authorization broke, zero if it works.
+	Since time.Time
+}
+
+// Broken reports whether the account's authorization is broken, so the
+// user has to authorize the application again.
+func (s AccountStatus) Broken() bool {
+	return s.Err != nil
+}
+
+// NewManager returns a Manager for the application identified by clientID
+// and clientSecret.
+//
+// httpClient sends the requests of every account. If nil,
+// http.DefaultClient is used. opts configure every Client the Manager
+// creates, including the app access token Client.
+func NewManager(clientID, clientSecret string, httpClient HTTPClient, opts ...Option) *Manager {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	m := &Manager{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		httpClient:   httpClient,
+		opts:         opts,
+		rateLimiter:  NewRateLimiter(nil),
+		accounts:     make(map[string]*account),
+	}
+
+	m.app = m.newClient()
+	m.app.SetTokenSource(NewAppTokenSource(m.app, clientSecret))
+
+	return m
+}
+
+// newClient returns a Client without token sharing the HTTPClient and
+// RateLimiter of m.
+func (m *Manager) newClient() *Client {
+	client := NewClient(&m.clientID, nil, m.httpClient, m.opts...)
+	client.SetRateLimiter(m.rateLimiter)
+	client.SetClientSecret(m.clientSecret)
+
+	return client
+}
+
+// App returns the Client authorized with an app access token.
+func (m *Manager) App() *Client {
+	return m.app
+}
+
+// AddAccount registers the account userID, whose user access token and
+// refresh token are kept in store, and returns its Client.
+//
+// The token is refreshed with the refresh token when it expires or is
+// rejected, so store must hold one. An account already registered as
+// userID is replaced.
+func (m *Manager) AddAccount(userID string, store TokenStore) (*Client, error) {
+	token, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load token: %w", err)
+	}
+
+	if token == nil || token.RefreshToken == "" {
+		return nil, fmt.Errorf("token of account %s has no refresh token", userID)
+	}
+
+	client := m.newClient()
+	client.SetTokenStore(store)
+
+	return m.add(userID, client), nil
+}
+
+// AddAccountSource registers the account userID, whose tokens are supplied
+// by source, and returns its Client.
+//
+// An account already registered as userID is replaced.
+func (m *Manager) AddAccountSource(userID string, source TokenSource) *Client {
+	client := m.newClient()
+	client.SetTokenSource(source)
+
+	return m.add(userID, client)
+}
+
+// add registers client as the Client of the account userID.
+func (m *Manager) add(userID string, client *Client) *Client {
+	acc := &account{
+		client: client,
+		status: AccountStatus{UserID: userID},
+	}
+
+	client.Use(acc.trackStatus)
+
+	m.mu.Lock()
+	m.accounts[userID] = acc
+	m.mu.Unlock()
+
+	return client
+}
+
+// RemoveAccount unregisters the account userID.
+func (m *Manager) RemoveAccount(userID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.accounts, userID)
+}
+
+// Account returns the Client of the account userID.
+// ok is false if no such account is registered.
+func (m *Manager) Account(userID string) (client *Client, ok bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	acc, ok := m.accounts[userID]
+	if !ok {
+		return nil, false
+	}
+
+	return acc.client, true
+}
+
+// For returns the Client to call an endpoint with on behalf of the first
+// of userIDs that is a registered account, such as the moderator ID and
+// then the broadcaster ID of SendShoutout.
+//
+// Without userIDs, the app access token Client is returned. Otherwise
+// ErrNoAccount is returned if none of userIDs is registered.
+func (m *Manager) For(userIDs ...string) (*Client, error) {
+	if len(userIDs) == 0 {
+		return m.app, nil
+	}
+
+	for _, userID := range userIDs {
+		if client, ok := m.Account(userID); ok {
+			return client, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %v", ErrNoAccount, userIDs)
+}
+
+// Status returns the authorization status of the account userID.
+// ok is false if no such account is registered.
+func (m *Manager) Status(userID string) (status AccountStatus, ok bool) {
+	m.mu.RLock()
+	acc, ok := m.accounts[userID]
+	m.mu.RUnlock()
+
+	if !ok {
+		return AccountStatus{}, false
+	}
+
+	return acc.currentStatus(), true
+}
+
+// Broken returns the status of every account whose authorization is
+// broken, ordered by user ID.
+func (m *Manager) Broken() []AccountStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var broken []AccountStatus
+
+	for _, acc := range m.accounts {
+		if status := acc.currentStatus(); status.Broken() {
+			broken = append(broken, status)
+		}
+	}
+
+	slices.SortFunc(broken, func(a, b AccountStatus) int {
+		return strings.Compare(a.UserID, b.UserID)
+	})
+
+	return broken
+}
+
+// Validate validates the token of every account, updating their status,
+// and returns the status of those whose authorization is broken.
+//
+// Errors other than invalid tokens, such as network errors, leave the
+// status of an account unchanged.
+func (m *Manager) Validate(ctx context.Context) []AccountStatus {
+	m.mu.RLock()
+	accounts := make([]*account, 0, len(m.accounts))
+	for _, acc := range m.accounts {
+		accounts = append(accounts, acc)
+	}
+	m.mu.RUnlock()
+
+	for _, acc := range accounts {
+		_, err := acc.client.ValidateToken(ctx)
+		acc.record(err)
+	}
+
+	return m.Broken()
+}
+
+// currentStatus returns the authorization status of a.
+func (a *account) currentStatus() AccountStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.status
+}
+
+// trackStatus is a Middleware recording the authorization status of a from
+// the outcome of its calls.
+func (a *account) trackStatus(next Handler) Handler {
+	return func(ctx context.Context, req *Request) (*Response, error) {
+		resp, err := next(ctx, req)
+		a.record(err)
+
+		return resp, err
+	}
+}
+
+// record updates the authorization status of a after a call that failed
+// with err, or succeeded if err is nil.
+func (a *account) record(err error) {
+	broken := authBroken(err)
+	if err != nil && !broken {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch {
+	case !broken:
+		a.status.Err = nil
+		a.status.Since = time.Time{}
+	case a.status.Err == nil:
+		a.status.Err = err
+		a.status.Since = time.Now()
+	default:
+		a.status.Err = err
+	}
+}
+
+// authBroken reports whether err shows that a token is invalid and could
+// not be renewed, rather than lacking a scope or failing for other reasons.
+func authBroken(err error) bool {
+	var oauthErr *OAuthError
+	if errors.As(err, &oauthErr) {
+		return oauthErr.StatusCode == http.StatusBadRequest || oauthErr.StatusCode == http.StatusUnauthorized
+	}
+
+	if errors.Is(err, errNoAccessToken) {
+		return true
+	}
+
+	return errors.Is(err, ErrUnauthorized) && !IsMissingScope(err)
+}
@@ -0,0 +1,184 @@
+package twitchhelix
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Request describes a Helix call passing through the middleware chain.
+type Request struct {
+	// Endpoint names the called endpoint after the Client method calling
+	// it, such as "GetUsers".
+	Endpoint string
+
+	// Method is the HTTP method.
+	Method string
+
+	// Path is the API path including the query string, without the base URL.
+	Path string
+
+	// Body is the value sent as the JSON request body, nil if none.
+	Body any
+
+	// Out is the value the JSON response body is decoded into, nil if the
+	// body is discarded.
+	Out any
+
+	// Header holds extra headers sent with the request, such as a request ID.
+	Header http.Header
+}
+
+// Response describes the outcome of a Helix call.
+type Response struct {
+	// StatusCode is the HTTP status code of the last attempt.
+	StatusCode int
+
+	// Header holds the response headers of the last attempt.
+	Header http.Header
+
+	// Out is the decoded response body, the same value as Request.Out.
+	Out any
+
+	// Latency is the time spent on the call, including retries.
+	Latency time.Duration
+}
+
+// Handler performs a Helix call.
+//
+// The returned Response can be nil if no response was received, in which
+// case the error explains why.
+type Handler func(ctx context.Context, req *Request) (*Response, error)
+
+// Middleware wraps a Handler to observe or alter Helix calls.
+//
+// A Middleware can short-circuit a call by returning without calling next.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware adds middlewares to the client, see Client.Use.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(c *Client) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+// Use adds middlewares to the client.
+//
+// Middlewares run in the order they were added, the first one seeing each
+// call first. They wrap the whole call, including token refreshes, rate
+// limit waits and retries.
+func (c *Client) Use(middlewares ...Middleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.middlewares = append(c.middlewares, middlewares...)
+}
+
+// handler returns the client's middleware chain wrapped around execute.
+func (c *Client) handler() Handler {
+	c.mu.RLock()
+	middlewares := c.middlewares
+	c.mu.RUnlock()
+
+	handler := c.execute
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return handler
+}
+
+// LoggingMiddleware logs every call with its endpoint, status and latency.
+//
+// Failed calls are logged at warn level, the others at debug level.
+func LoggingMiddleware(logger *zap.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			start := time.Now()
+
+			resp, err := next(ctx, req)
+
+			fields := []zap.Field{
+				zap.String("endpoint", req.Endpoint),
+				zap.String("method", req.Method),
+				zap.String("path", req.Path),
+				zap.Duration("latency", time.Since(start)),
+			}
+
+			if resp != nil {
+				fields = append(fields, zap.Int("status", resp.StatusCode))
+			}
+
+			if err != nil {
+				logger.Warn("helix request failed", append(fields, zap.Error(err))...)
+			} else {
+				logger.Debug("helix request", fields...)
+			}
+
+			return resp, err
+		}
+	}
+}
+
+// requestIDKey is the context key of the request ID.
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID id,
+// which RequestIDMiddleware sends with the request.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, "" if none.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+
+	return id
+}
+
+// RequestIDMiddleware sends the request ID of the call's context in the
+// X-Request-Id header.
+//
+// If the context carries no request ID and newID is not nil, newID
+// generates one, which is also added to the context passed down the chain.
+func RequestIDMiddleware(newID func() string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			id := RequestIDFromContext(ctx)
+			if id == "" && newID != nil {
+				id = newID()
+				ctx = ContextWithRequestID(ctx, id)
+			}
+
+			if id != "" {
+				req.Header.Set(requestIDHeader, id)
+			}
+
+			return next(ctx, req)
+		}
+	}
+}
+
+// DryRunMiddleware stops every call that is not a GET request from being
+// sent and reports it as successful with status 204 No Content.
+//
+// Read endpoints still reach Twitch, so the rest of an application keeps
+// working while no changes are made.
+func DryRunMiddleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			if req.Method == http.MethodGet {
+				return next(ctx, req)
+			}
+
+			return &Response{
+				StatusCode: http.StatusNoContent,
+				Header:     make(http.Header),
+				Out:        req.Out,
+			}, nil
+		}
+	}
+}
@@ -45,6 +45,8 @@ type ContentClassificationLabel struct {
 // ModifyChannelInformation updates a broadcaster's channel settings.
 // At least one field in RequestModifyChannelInformation must be included.
 // Requires broadcaster authentication.
+//
+// Requires a user access token with the channel:manage:broadcast scope.
 func (c *Client) ModifyChannelInformation(ctx context.Context, req RequestModifyChannelInformation, broadcasterID string) error {
 	query := "channels?broadcaster_id=" + broadcasterID
 
@@ -0,0 +1,103 @@
+package twitchhelix
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sentinel errors matched by OAuthError through errors.Is.
+var (
+	// ErrInvalidGrant matches token requests rejected because the refresh
+	// token, authorization code or device code is invalid or expired.
+	ErrInvalidGrant = errors.New("invalid grant")
+
+	// ErrInvalidClient matches token requests rejected because the client
+	// ID or client secret is invalid.
+	ErrInvalidClient = errors.New("invalid client")
+)
+
+// oauthMessageCodes maps messages Twitch sends instead of an OAuth error
+// code to that code.
+var oauthMessageCodes = map[string]string{
+	"invalid refresh token":      "invalid_grant",
+	"invalid authorization code": "invalid_grant",
+	"invalid client":             "invalid_client",
+	"invalid client secret":      "invalid_client",
+}
+
+// OAuthError is returned when a Twitch OAuth endpoint rejects a request.
+type OAuthError struct {
+	// StatusCode is the HTTP status code returned by the endpoint.
+	StatusCode int
+
+	// Code is the OAuth error code, such as "authorization_pending".
+	// Known messages, such as "Invalid refresh token", are translated to
+	// their code. It is empty otherwise if the response did not name one.
+	Code string
+
+	// Message is the "message" field of the response.
+	Message string
+
+	// Body contains the raw response body.
+	Body []byte
+}
+
+// newOAuthError reads the error response body of an OAuth endpoint.
+func newOAuthError(statusCode int, body []byte) *OAuthError {
+	oauthErr := &OAuthError{
+		StatusCode: statusCode,
+		Body:       body,
+	}
+
+	var envelope struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+
+	if json.Unmarshal(body, &envelope) != nil {
+		return oauthErr
+	}
+
+	oauthErr.Message = envelope.Message
+
+	// Twitch reports some codes, such as authorization_pending, in the
+	// message field instead of the error field.
+	switch {
+	case isOAuthCode(envelope.Error):
+		oauthErr.Code = envelope.Error
+	case isOAuthCode(envelope.Message):
+		oauthErr.Code = envelope.Message
+	default:
+		oauthErr.Code = oauthMessageCodes[strings.ToLower(envelope.Message)]
+	}
+
+	return oauthErr
+}
+
+// isOAuthCode reports whether s looks like an OAuth error code, such as
+// "invalid_grant", rather than a sentence.
+func isOAuthCode(s string) bool {
+	return s != "" && !strings.ContainsAny(s, " ") && strings.ToLower(s) == s
+}
+
+func (e *OAuthError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("Twitch OAuth Error(%d): %s", e.StatusCode, e.Message)
+	}
+
+	return fmt.Sprintf("Twitch OAuth Error(%d): %s", e.StatusCode, e.Body)
+}
+
+// Is reports whether target is the sentinel error for the code of e.
+func (e *OAuthError) Is(target error) bool {
+	switch e.Code {
+	case "invalid_grant":
+		return target == ErrInvalidGrant
+	case "invalid_client":
+		return target == ErrInvalidClient
+	}
+
+	return false
+}
@@ -0,0 +1,80 @@
+package twitchhelix
+
+import (
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultBaseURL is the base URL of the Twitch Helix API.
+	defaultBaseURL = "https://api.twitch.tv/helix/"
+
+	// defaultAuthURL is the base URL of the Twitch OAuth endpoints.
+	defaultAuthURL = "https://id.twitch.tv/oauth2/"
+)
+
+// Option configures a Client created by NewClient.
+type Option func(*Client)
+
+// WithBaseURL sends Helix requests to baseURL instead of
+// https://api.twitch.tv/helix/, for example to the Twitch CLI mock API.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = withTrailingSlash(baseURL)
+	}
+}
+
+// WithAuthURL sends OAuth requests, such as token refreshes, to authURL
+// instead of https://id.twitch.tv/oauth2/.
+func WithAuthURL(authURL string) Option {
+	return func(c *Client) {
+		c.authURL = withTrailingSlash(authURL)
+	}
+}
+
+// WithHTTPClient makes the client send every request, including token
+// refreshes, with httpClient. A nil httpClient is ignored.
+func WithHTTPClient(httpClient HTTPClient) Option {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// WithUserAgent sends userAgent as the User-Agent header of every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithLogger makes the client log to logger instead of the global zap
+// logger. A nil logger disables logging.
+func WithLogger(logger *zap.Logger) Option {
+	return func(c *Client) {
+		if logger == nil {
+			logger = zap.NewNop()
+		}
+
+		c.logger = logger
+	}
+}
+
+// withTrailingSlash appends a slash to url unless it already ends with one.
+func withTrailingSlash(url string) string {
+	if strings.HasSuffix(url, "/") {
+		return url
+	}
+
+	return url + "/"
+}
+
+// setUserAgent sets the User-Agent header of req if the client has one.
+func (c *Client) setUserAgent(req *http.Request) {
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+}
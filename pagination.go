@@ -1,5 +1,10 @@
 package twitchhelix
 
+import (
+	"context"
+	"iter"
+)
+
 // Pagination represents the cursor-based pagination information
 // returned by Twitch API endpoints.
 //
@@ -12,3 +17,108 @@ type Pagination struct {
 	// If empty, there are no more pages.
 	Cursor string `json:"cursor,omitempty"`
 }
+
+// PageFunc fetches the page of a cursor-based endpoint starting at cursor,
+// "" for the first page. It returns the items of the page and the cursor
+// of the following page, "" if there is none.
+type PageFunc[T any] func(ctx context.Context, cursor string) (items []T, next string, err error)
+
+// Paginate returns an iterator over the items of every page of a
+// cursor-based endpoint, starting at cursor.
+//
+// Pages are fetched lazily as the iterator is consumed, until fetch returns
+// an empty cursor or an empty page. If fetch fails or ctx is done, the
+// error is yielded with the zero value of T and iteration stops. Every
+// iteration starts over at cursor.
+func Paginate[T any](ctx context.Context, cursor string, fetch PageFunc[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		cursor := cursor
+
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+
+				return
+			}
+
+			items, next, err := fetch(ctx, cursor)
+			if err != nil {
+				yield(zero, err)
+
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if next == "" || len(items) == 0 {
+				return
+			}
+
+			cursor = next
+		}
+	}
+}
+
+// Collect gathers the items of seq into a slice.
+//
+// At most limit items are collected; limit <= 0 collects every item. The
+// first error stops collection and is returned with the items gathered so
+// far.
+func Collect[T any](seq iter.Seq2[T, error], limit int) ([]T, error) {
+	var items []T
+
+	for item, err := range seq {
+		if err != nil {
+			return items, err
+		}
+
+		items = append(items, item)
+
+		if limit > 0 && len(items) >= limit {
+			break
+		}
+	}
+
+	return items, nil
+}
+
+// cursor returns the cursor stored in p, "" if p is nil.
+func (p *Pagination) cursor() string {
+	if p == nil {
+		return ""
+	}
+
+	return p.Cursor
+}
+
+// startCursor picks the cursor to start paging from and whether to page
+// backwards, which is the case when only before is set.
+func startCursor(after, before *string) (cursor string, backward bool) {
+	switch {
+	case after != nil:
+		return *after, false
+	case before != nil:
+		return *before, true
+	}
+
+	return "", false
+}
+
+// pageCursors returns the after and before request parameters selecting
+// the page at cursor.
+func pageCursors(cursor string, backward bool) (after, before *string) {
+	switch {
+	case cursor == "":
+		return nil, nil
+	case backward:
+		return nil, &cursor
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,208 @@
+package twitchhelix
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and waits for durations to pass.
+//
+// It exists so tests can replace the system clock with a fake one.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After returns a channel that receives once d has elapsed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the Clock backed by the time package.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// defaultRateLimitWait is how long a 429 response without a Ratelimit-Reset
+// header blocks further requests.
+const defaultRateLimitWait = time.Second
+
+// maxRateLimitRetries is how many times a request rejected with 429 Too Many
+// Requests is retried after the bucket resets.
+const maxRateLimitRetries = 3
+
+// RateLimitBucket is the state of a Helix rate limit bucket, as reported by
+// the Ratelimit-* response headers.
+type RateLimitBucket struct {
+	// Limit is the number of points the bucket holds when full.
+	Limit int
+
+	// Remaining is the number of points left in the bucket.
+	Remaining int
+
+	// Reset is when the bucket is refilled to Limit.
+	Reset time.Time
+}
+
+// RateLimiter tracks Helix rate limit buckets per access token.
+//
+// Requests block while their token's bucket is empty until the bucket
+// resets. A RateLimiter is safe for concurrent use and can be shared by
+// several Clients using the same tokens.
+type RateLimiter struct {
+	// clock is used to tell the time and wait for resets.
+	clock Clock
+
+	// mu guards buckets.
+	mu sync.Mutex
+
+	// buckets holds the bucket of each access token.
+	buckets map[string]*RateLimitBucket
+}
+
+// NewRateLimiter returns an empty RateLimiter.
+// clock can be nil, in which case the system clock is used.
+func NewRateLimiter(clock Clock) *RateLimiter {
+	if clock == nil {
+		clock = systemClock{}
+	}
+
+	return &RateLimiter{
+		clock:   clock,
+		buckets: make(map[string]*RateLimitBucket),
+	}
+}
+
+// Bucket returns the bucket state of token.
+// ok is false if no response has been seen for token yet.
+func (l *RateLimiter) Bucket(token string) (bucket RateLimitBucket, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[token]
+	if !ok {
+		return RateLimitBucket{}, false
+	}
+
+	return *b, true
+}
+
+// wait blocks until the bucket of token has a point left and takes it.
+func (l *RateLimiter) wait(ctx context.Context, token string) error {
+	for {
+		l.mu.Lock()
+
+		b, ok := l.buckets[token]
+		if !ok {
+			l.mu.Unlock()
+
+			return nil
+		}
+
+		now := l.clock.Now()
+
+		if b.Remaining <= 0 && !now.Before(b.Reset) {
+			b.Remaining = b.Limit
+		}
+
+		if b.Remaining > 0 || b.Limit == 0 {
+			b.Remaining--
+			l.mu.Unlock()
+
+			return nil
+		}
+
+		delay := b.Reset.Sub(now)
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-l.clock.After(delay):
+		}
+	}
+}
+
+// update records the bucket state reported by header for token.
+func (l *RateLimiter) update(token string, header http.Header) {
+	bucket, ok := parseRateLimit(header)
+	if !ok {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.buckets[token] = &bucket
+}
+
+// parseRateLimit reads the Ratelimit-* headers.
+// ok is false if any of them is missing or malformed.
+func parseRateLimit(header http.Header) (bucket RateLimitBucket, ok bool) {
+	limit, errLimit := strconv.Atoi(header.Get("Ratelimit-Limit"))
+	remaining, errRemaining := strconv.Atoi(header.Get("Ratelimit-Remaining"))
+	reset, errReset := strconv.ParseInt(header.Get("Ratelimit-Reset"), 10, 64)
+
+	if errLimit != nil || errRemaining != nil || errReset != nil {
+		return RateLimitBucket{}, false
+	}
+
+	return RateLimitBucket{
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     time.Unix(reset, 0),
+	}, true
+}
+
+// exhaust marks the bucket of token as empty after a 429 response, so
+// requests wait for the reset reported by header.
+func (l *RateLimiter) exhaust(token string, header http.Header) {
+	l.update(token, header)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[token]
+	if !ok {
+		b = &RateLimitBucket{Limit: 1}
+		l.buckets[token] = b
+	}
+
+	b.Remaining = 0
+
+	if now := l.clock.Now(); !b.Reset.After(now) {
+		b.Reset = now.Add(defaultRateLimitWait)
+	}
+}
+
+// SetRateLimiter replaces the rate limiter of the client.
+//
+// Clients sharing tokens should share a RateLimiter so they see the same
+// buckets. If limiter is nil, the client gets a new RateLimiter of its own.
+func (c *Client) SetRateLimiter(limiter *RateLimiter) {
+	if limiter == nil {
+		limiter = NewRateLimiter(nil)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rateLimiter = limiter
+}
+
+// RateLimit returns the rate limit bucket of the current access token.
+// ok is false if no response has been seen for the token yet.
+func (c *Client) RateLimit() (bucket RateLimitBucket, ok bool) {
+	return c.limiter().Bucket(c.GetToken())
+}
+
+// limiter returns the rate limiter of the client.
+func (c *Client) limiter() *RateLimiter {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.rateLimiter
+}
@@ -0,0 +1,251 @@
+package twitchhelix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock whose time only moves when it is waited on.
+type fakeClock struct {
+	// block makes After return a channel that never receives.
+	block bool
+
+	// mu guards now and waits.
+	mu sync.Mutex
+
+	// now is the current time.
+	now time.Time
+
+	// waits are the durations passed to After.
+	waits []time.Duration
+}
+
+// newFakeClock returns a fakeClock set to a fixed time.
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(1_700_000_000, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// After records d and advances the clock by d right away, unless block is
+// set.
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.waits = append(c.waits, d)
+
+	ch := make(chan time.Time, 1)
+	if !c.block {
+		c.now = c.now.Add(d)
+		ch <- c.now
+	}
+
+	return ch
+}
+
+// Waits returns the durations waited for so far.
+func (c *fakeClock) Waits() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]time.Duration(nil), c.waits...)
+}
+
+// rateLimitHeader returns Ratelimit-* headers for a bucket.
+func rateLimitHeader(limit, remaining int, reset time.Time) http.Header {
+	header := make(http.Header)
+	header.Set("Ratelimit-Limit", strconv.Itoa(limit))
+	header.Set("Ratelimit-Remaining", strconv.Itoa(remaining))
+	header.Set("Ratelimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+
+	return header
+}
+
+func TestRateLimiterWaitUnknownToken(t *testing.T) {
+	clock := newFakeClock()
+	limiter := NewRateLimiter(clock)
+
+	if err := limiter.wait(context.Background(), "token"); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+
+	if waits := clock.Waits(); len(waits) != 0 {
+		t.Errorf("waited %v for a token without bucket", waits)
+	}
+}
+
+func TestRateLimiterWaitTakesPoints(t *testing.T) {
+	clock := newFakeClock()
+	limiter := NewRateLimiter(clock)
+	limiter.update("token", rateLimitHeader(800, 2, clock.Now().Add(10*time.Second)))
+
+	for i := range 2 {
+		if err := limiter.wait(context.Background(), "token"); err != nil {
+			t.Fatalf("wait %d: %v", i, err)
+		}
+	}
+
+	if waits := clock.Waits(); len(waits) != 0 {
+		t.Fatalf("waited %v with points left", waits)
+	}
+
+	if err := limiter.wait(context.Background(), "token"); err != nil {
+		t.Fatalf("wait on empty bucket: %v", err)
+	}
+
+	if waits := clock.Waits(); len(waits) != 1 || waits[0] != 10*time.Second {
+		t.Errorf("waits = %v, want [10s]", waits)
+	}
+
+	bucket, ok := limiter.Bucket("token")
+	if !ok || bucket.Remaining != 799 {
+		t.Errorf("bucket after reset = %+v, %v, want 799 remaining", bucket, ok)
+	}
+}
+
+func TestRateLimiterWaitContextDone(t *testing.T) {
+	clock := newFakeClock()
+	clock.block = true
+
+	limiter := NewRateLimiter(clock)
+	limiter.update("token", rateLimitHeader(800, 0, clock.Now().Add(time.Minute)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := limiter.wait(ctx, "token")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wait = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestRateLimiterExhaust(t *testing.T) {
+	clock := newFakeClock()
+	limiter := NewRateLimiter(clock)
+
+	limiter.exhaust("token", http.Header{})
+
+	bucket, ok := limiter.Bucket("token")
+	if !ok {
+		t.Fatal("no bucket after exhaust")
+	}
+
+	want := RateLimitBucket{Limit: 1, Remaining: 0, Reset: clock.Now().Add(defaultRateLimitWait)}
+	if bucket != want {
+		t.Errorf("bucket without headers = %+v, want %+v", bucket, want)
+	}
+
+	reset := clock.Now().Add(30 * time.Second)
+	limiter.exhaust("other", rateLimitHeader(800, 5, reset))
+
+	bucket, _ = limiter.Bucket("other")
+	if bucket.Remaining != 0 || !bucket.Reset.Equal(reset) {
+		t.Errorf("bucket with headers = %+v, want 0 remaining until %v", bucket, reset)
+	}
+
+	limiter.exhaust("past", rateLimitHeader(800, 0, clock.Now().Add(-time.Second)))
+
+	bucket, _ = limiter.Bucket("past")
+	if want := clock.Now().Add(defaultRateLimitWait); !bucket.Reset.Equal(want) {
+		t.Errorf("bucket reset in the past = %v, want %v", bucket.Reset, want)
+	}
+}
+
+// newRateLimitedClient returns a client sending requests to a server that
+// rejects the first rejections requests with 429 Too Many Requests, along
+// with the fake clock of its rate limiter and the number of requests the
+// server received.
+func newRateLimitedClient(t *testing.T, rejections int) (*Client, *fakeClock, *atomic.Int32) {
+	t.Helper()
+
+	clock := newFakeClock()
+	requests := new(atomic.Int32)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		reset := clock.Now().Add(5 * time.Second)
+
+		if n <= rejections {
+			for key, values := range rateLimitHeader(800, 0, reset) {
+				w.Header()[key] = values
+			}
+
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"error":"Too Many Requests","status":429,"message":"rate limited"}`)
+
+			return
+		}
+
+		for key, values := range rateLimitHeader(800, 799, reset) {
+			w.Header()[key] = values
+		}
+
+		fmt.Fprint(w, `{"data":[{"id":"1","login":"user"}]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	clientID := "client-id"
+	token := "token"
+
+	client := NewClient(&clientID, &token, nil, WithBaseURL(server.URL+"/"))
+	client.SetRateLimiter(NewRateLimiter(clock))
+
+	return client, clock, requests
+}
+
+func TestClientRetriesRateLimitedRequest(t *testing.T) {
+	client, clock, requests := newRateLimitedClient(t, 2)
+
+	resp, err := client.GetUsers(context.Background(), GetUsersRequest{ID: []string{"1"}})
+	if err != nil {
+		t.Fatalf("GetUsers: %v", err)
+	}
+
+	if len(resp.Data) != 1 || resp.Data[0].ID != "1" {
+		t.Errorf("users = %+v, want user 1", resp.Data)
+	}
+
+	if n := requests.Load(); n != 3 {
+		t.Errorf("server received %d requests, want 3", n)
+	}
+
+	waits := clock.Waits()
+	if len(waits) != 2 {
+		t.Fatalf("waits = %v, want 2 waits for the reset", waits)
+	}
+
+	for _, wait := range waits {
+		if wait != 5*time.Second {
+			t.Errorf("waited %v, want 5s", wait)
+		}
+	}
+}
+
+func TestClientGivesUpRateLimitedRequest(t *testing.T) {
+	client, _, requests := newRateLimitedClient(t, maxRateLimitRetries+1)
+
+	_, err := client.GetUsers(context.Background(), GetUsersRequest{ID: []string{"1"}})
+
+	var apiErr *TwitchAPIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("GetUsers = %v, want 429 TwitchAPIError", err)
+	}
+
+	if n := requests.Load(); n != maxRateLimitRetries+1 {
+		t.Errorf("server received %d requests, want %d", n, maxRateLimitRetries+1)
+	}
+}
@@ -2,12 +2,8 @@ package twitchhelix
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"net/url"
-	"strings"
 	"time"
 )
 
@@ -17,52 +13,77 @@ type NewToken struct {
 	ExpiresIn    int      `json:"expires_in"`
 	Scope        []string `json:"scope"`
 	TokenType    string   `json:"token_type"`
+
+	// Expiry is when the access token expires, computed from ExpiresIn
+	// when the token was obtained. It is zero if unknown.
+	Expiry time.Time `json:"expiry,omitzero"`
+}
+
+// expiryFromNow returns the time expiresIn seconds from now, or the zero
+// time if expiresIn is not positive.
+func expiryFromNow(expiresIn int) time.Time {
+	if expiresIn <= 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(time.Duration(expiresIn) * time.Second)
 }
 
-const twitchRefreshLink = "https://id.twitch.tv/oauth2/token"
+// refreshTimeout bounds Refresh and RefreshApp, which take no context.
+const refreshTimeout = 15 * time.Second
 
+// Refresh exchanges refreshToken for a new user access token and saves it
+// to the client's token store.
+//
+// It is RefreshContext with a timeout of 15 seconds.
 func (c *Client) Refresh(clientSecret, refreshToken string) (*NewToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+	defer cancel()
+
+	return c.RefreshContext(ctx, clientSecret, refreshToken)
+}
+
+// RefreshContext exchanges refreshToken for a new user access token and
+// saves it to the client's token store.
+//
+// A rejected request is reported as an *OAuthError, which matches
+// ErrInvalidGrant if the refresh token is invalid and ErrInvalidClient if
+// the client credentials are. The stored token is only replaced once a new
+// one was obtained.
+//
+// If the token was obtained but could not be saved, it is returned together
+// with the error.
+func (c *Client) RefreshContext(ctx context.Context, clientSecret, refreshToken string) (*NewToken, error) {
 	data := url.Values{}
 	data.Add("client_id", *c.clientID)
 	data.Add("client_secret", clientSecret)
 	data.Add("grant_type", "refresh_token")
 	data.Add("refresh_token", refreshToken)
 
-	tokenRequestData := data.Encode()
-
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	defer cancel()
+	var tokenData NewToken
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, twitchRefreshLink, strings.NewReader(tokenRequestData))
+	err := c.postOAuth(ctx, "token", data, &tokenData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create new http request: %w", err)
+		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	client := http.Client{}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+	if tokenData.AccessToken == "" {
+		return nil, errNoAccessToken
 	}
 
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read body: %w", err)
+	// Twitch may rotate the refresh token; keep using the old one if
+	// none was returned.
+	if tokenData.RefreshToken == "" {
+		tokenData.RefreshToken = refreshToken
 	}
 
-	var tokenData NewToken
+	tokenData.Expiry = expiryFromNow(tokenData.ExpiresIn)
 
-	err = json.Unmarshal(body, &tokenData)
+	err = c.saveToken(&tokenData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return &tokenData, fmt.Errorf("failed to save token: %w", err)
 	}
 
-	c.token = &tokenData.AccessToken
-
 	return &tokenData, nil
 }
 
@@ -72,46 +93,66 @@ type NewAppToken struct {
 	TokenType   string `json:"token_type"`
 }
 
+// RefreshApp requests a new app access token with the client credentials
+// grant and saves it to the client's token store.
+//
+// It is RefreshAppContext with a timeout of 15 seconds.
 func (c *Client) RefreshApp(clientSecret string) (*NewAppToken, error) {
-	data := url.Values{}
-	data.Add("client_id", *c.clientID)
-	data.Add("client_secret", clientSecret)
-	data.Add("grant_type", "client_credentials")
-
-	tokenRequestData := data.Encode()
-
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, twitchRefreshLink, strings.NewReader(tokenRequestData))
+	return c.RefreshAppContext(ctx, clientSecret)
+}
+
+// RefreshAppContext requests a new app access token with the client
+// credentials grant and saves it to the client's token store.
+//
+// A rejected request is reported as an *OAuthError, which matches
+// ErrInvalidClient if the client credentials are invalid. The stored token
+// is only replaced once a new one was obtained.
+//
+// If the token was obtained but could not be saved, it is returned together
+// with the error.
+func (c *Client) RefreshAppContext(ctx context.Context, clientSecret string) (*NewAppToken, error) {
+	token, err := c.requestAppToken(ctx, clientSecret)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create new http request: %w", err)
+		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	client := http.Client{}
+	appToken := &NewAppToken{
+		AccessToken: token.AccessToken,
+		ExpiresIn:   token.ExpiresIn,
+		TokenType:   token.TokenType,
+	}
 
-	resp, err := client.Do(req)
+	err = c.saveToken(token)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return appToken, fmt.Errorf("failed to save token: %w", err)
 	}
 
-	defer resp.Body.Close()
+	return appToken, nil
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read body: %w", err)
-	}
+// requestAppToken obtains a new app access token with the client
+// credentials grant.
+func (c *Client) requestAppToken(ctx context.Context, clientSecret string) (*NewToken, error) {
+	data := url.Values{}
+	data.Add("client_id", *c.clientID)
+	data.Add("client_secret", clientSecret)
+	data.Add("grant_type", "client_credentials")
 
-	var tokenData NewAppToken
+	var tokenData NewToken
 
-	err = json.Unmarshal(body, &tokenData)
+	err := c.postOAuth(ctx, "token", data, &tokenData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return nil, err
+	}
+
+	if tokenData.AccessToken == "" {
+		return nil, errNoAccessToken
 	}
 
-	c.token = &tokenData.AccessToken
+	tokenData.Expiry = expiryFromNow(tokenData.ExpiresIn)
 
 	return &tokenData, nil
 }
@@ -0,0 +1,162 @@
+package twitchhelix
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// RetryPolicy controls how the client retries requests that failed with a
+// network error or a transient Helix status code.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	//
+	// Values below 1 are treated as 1.
+	MaxAttempts int
+
+	// BaseDelay is the upper bound of the delay before the first retry.
+	// It doubles with every further retry.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between two attempts. Zero means no cap.
+	MaxDelay time.Duration
+
+	// StatusCodes lists the HTTP status codes that are retried.
+	StatusCodes []int
+
+	// Methods lists the HTTP methods that are retried.
+	//
+	// Requests with other methods are only retried if their context was
+	// marked with AllowRetry. By default POST is not listed, so requests
+	// like MakeClip or StartRaid are never sent twice.
+	Methods []string
+}
+
+// DefaultRetryPolicy returns a policy that makes up to 3 attempts of
+// idempotent requests failing with a network error or a 5xx status code.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   250 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+		StatusCodes: []int{
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		Methods: []string{
+			http.MethodGet,
+			http.MethodHead,
+			http.MethodPut,
+			http.MethodDelete,
+			http.MethodOptions,
+		},
+	}
+}
+
+// allowRetryKey is the context key set by AllowRetry.
+type allowRetryKey struct{}
+
+// AllowRetry returns a copy of ctx that lets the retry policy retry the
+// request even if its HTTP method is not in RetryPolicy.Methods.
+//
+// Only use it for requests that are safe to send twice.
+func AllowRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allowRetryKey{}, true)
+}
+
+// SetRetryPolicy sets the retry policy of the client.
+// A nil policy, the default, disables retries.
+func (c *Client) SetRetryPolicy(policy *RetryPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.retryPolicy = policy
+}
+
+// retry returns the retry policy of the client, nil if retries are disabled.
+func (c *Client) retry() *RetryPolicy {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.retryPolicy
+}
+
+// shouldRetry reports whether a request with method that failed with err
+// on attempt should be attempted again.
+func (p *RetryPolicy) shouldRetry(ctx context.Context, method string, attempt int, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+
+	allowed, _ := ctx.Value(allowRetryKey{}).(bool)
+	if !allowed && !slices.Contains(p.Methods, method) {
+		return false
+	}
+
+	var apiErr *TwitchAPIError
+	if errors.As(err, &apiErr) {
+		return slices.Contains(p.StatusCodes, apiErr.StatusCode)
+	}
+
+	var urlErr *url.Error
+
+	return errors.As(err, &urlErr)
+}
+
+// backoff returns a random delay before the retry following attempt.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	return jitter(p.BaseDelay, p.MaxDelay, attempt-1)
+}
+
+// jitter returns a random delay of up to base doubled the given number of
+// times, capped to maxDelay unless it is zero or negative. A base of zero
+// or less uses maxDelay as the upper bound.
+func jitter(base, maxDelay time.Duration, doublings int) time.Duration {
+	delay := base
+	if delay <= 0 {
+		delay = maxDelay
+	}
+
+	for range doublings {
+		if (maxDelay > 0 && delay >= maxDelay) || delay > math.MaxInt64/2 {
+			break
+		}
+
+		delay *= 2
+	}
+
+	if maxDelay > 0 && delay > maxDelay {
+		delay = maxDelay
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	return rand.N(delay + 1)
+}
+
+// sleep waits for delay, returning false without waiting if ctx would
+// expire first.
+func sleep(ctx context.Context, delay time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return false
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
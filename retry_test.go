@@ -0,0 +1,50 @@
+package twitchhelix
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		attempt int
+		max     time.Duration
+	}{
+		{"first retry", RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute}, 1, time.Second},
+		{"doubles", RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute}, 3, 4 * time.Second},
+		{"capped", RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}, 10, 5 * time.Second},
+		{"no cap", RetryPolicy{BaseDelay: time.Second}, 4, 8 * time.Second},
+		{"no base", RetryPolicy{MaxDelay: time.Second}, 2, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var longest time.Duration
+
+			for range 1000 {
+				delay := tt.policy.backoff(tt.attempt)
+				if delay < 0 || delay > tt.max {
+					t.Fatalf("backoff(%d) = %v, want between 0 and %v", tt.attempt, delay, tt.max)
+				}
+
+				longest = max(longest, delay)
+			}
+
+			if longest < tt.max/2 {
+				t.Errorf("longest of 1000 delays is %v, want close to %v", longest, tt.max)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoffWithoutCapDoesNotOverflow(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second}
+
+	for attempt := 1; attempt <= 200; attempt++ {
+		if delay := policy.backoff(attempt); delay < 0 {
+			t.Fatalf("backoff(%d) = %v, want a delay of at least 0", attempt, delay)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package twitchhelix
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// TokenType is the kind of access token an endpoint accepts.
+type TokenType int
+
+const (
+	// AnyToken is accepted by endpoints taking both user and app access
+	// tokens.
+	AnyToken TokenType = iota
+
+	// UserToken is a user access token.
+	UserToken
+
+	// AppToken is an app access token.
+	AppToken
+)
+
+func (t TokenType) String() string {
+	switch t {
+	case UserToken:
+		return "user access token"
+	case AppToken:
+		return "app access token"
+	}
+
+	return "user or app access token"
+}
+
+// ScopeRequirement describes the access token a Client method needs.
+//
+// Scopes apply to user access tokens; app access tokens carry no scopes.
+type ScopeRequirement struct {
+	// Method is the name of the Client method.
+	Method string
+
+	// TokenType is the kind of access token the method accepts.
+	TokenType TokenType
+
+	// Scopes are the scopes the token must all have.
+	Scopes []string
+
+	// AnyOf lists scopes of which the token must have at least one.
+	AnyOf []string
+}
+
+// Requirement returns the access token the Client method named method
+// needs, such as "SendShoutout". ok is false if method is unknown.
+func Requirement(method string) (requirement ScopeRequirement, ok bool) {
+	e, ok := lookupMethod(method)
+	if !ok {
+		return ScopeRequirement{}, false
+	}
+
+	return e.requirement(method), true
+}
+
+// RequiredScopes returns the space separated scopes a user access token
+// needs to call every one of methods, for example to build an authorization
+// URL during onboarding.
+//
+// Where a method accepts one of several scopes, the first, least privileged
+// one is included unless another method already requires one of them.
+// Unknown methods are ignored.
+func RequiredScopes(methods ...string) string {
+	var scopes []string
+
+	for _, method := range methods {
+		e, ok := lookupMethod(method)
+		if !ok {
+			continue
+		}
+
+		for _, scope := range e.Scopes {
+			if !slices.Contains(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	for _, method := range methods {
+		e, ok := lookupMethod(method)
+		if !ok || len(e.AnyOf) == 0 {
+			continue
+		}
+
+		if !slices.ContainsFunc(e.AnyOf, func(scope string) bool { return slices.Contains(scopes, scope) }) {
+			scopes = append(scopes, e.AnyOf[0])
+		}
+	}
+
+	slices.Sort(scopes)
+
+	return strings.Join(scopes, " ")
+}
+
+// requirement returns the requirement of e as reported for method.
+func (e endpoint) requirement(method string) ScopeRequirement {
+	return ScopeRequirement{
+		Method:    method,
+		TokenType: e.TokenType,
+		Scopes:    slices.Clone(e.Scopes),
+		AnyOf:     slices.Clone(e.AnyOf),
+	}
+}
+
+// ScopeError is returned instead of sending a request, when scope checking
+// is enabled and the client's token is known not to satisfy the method.
+//
+// IsMissingScope reports true for it if the token lacks scopes.
+type ScopeError struct {
+	// Method is the name of the Client method.
+	Method string
+
+	// Missing are the required scopes the token lacks.
+	Missing []string
+
+	// AnyOf is set if the token has none of the scopes of which the method
+	// needs at least one.
+	AnyOf []string
+
+	// TokenType is the kind of access token the method needs, set if the
+	// token is of another kind.
+	TokenType TokenType
+}
+
+func (e *ScopeError) Error() string {
+	var problems []string
+
+	if e.TokenType != AnyToken {
+		problems = append(problems, "a "+e.TokenType.String())
+	}
+
+	if len(e.Missing) > 0 {
+		problems = append(problems, "scopes "+strings.Join(e.Missing, ", "))
+	}
+
+	if len(e.AnyOf) > 0 {
+		problems = append(problems, "one of scopes "+strings.Join(e.AnyOf, ", "))
+	}
+
+	return fmt.Sprintf("%s requires %s", e.Method, strings.Join(problems, " and "))
+}
+
+// WithScopeCheck makes the client check the scopes and kind of its token
+// before every request, see SetScopeCheck.
+func WithScopeCheck() Option {
+	return func(c *Client) {
+		c.scopeCheck = true
+	}
+}
+
+// SetScopeCheck enables or disables checking the token before requests.
+//
+// When enabled, requests the token is known not to satisfy fail with a
+// *ScopeError without being sent. The token is known from the last
+// ValidateToken call, or else from the scopes of the stored token; nothing
+// is checked while neither is available.
+func (c *Client) SetScopeCheck(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.scopeCheck = enabled
+}
+
+// checkScopes returns a *ScopeError if scope checking is enabled and the
+// client's token is known not to satisfy req.
+func (c *Client) checkScopes(req *Request) error {
+	c.mu.RLock()
+	enabled := c.scopeCheck
+	c.mu.RUnlock()
+
+	if !enabled {
+		return nil
+	}
+
+	e, ok := lookupEndpoint(req.Method, req.Path, req.Body)
+	if !ok {
+		return nil
+	}
+
+	tokenType := AnyToken
+
+	var granted []string
+
+	if info := c.TokenInfo(); info != nil {
+		granted = info.Scopes
+		tokenType = UserToken
+
+		if info.UserID == "" {
+			tokenType = AppToken
+		}
+	} else {
+		granted = c.Scopes()
+		if granted == nil {
+			return nil
+		}
+	}
+
+	scopeErr := &ScopeError{Method: e.Name}
+
+	if e.TokenType != AnyToken && tokenType != AnyToken && e.TokenType != tokenType {
+		scopeErr.TokenType = e.TokenType
+	}
+
+	// App access tokens carry no scopes.
+	if tokenType != AppToken {
+		for _, scope := range e.Scopes {
+			if !slices.Contains(granted, scope) {
+				scopeErr.Missing = append(scopeErr.Missing, scope)
+			}
+		}
+
+		if len(e.AnyOf) > 0 && !slices.ContainsFunc(e.AnyOf, func(scope string) bool { return slices.Contains(granted, scope) }) {
+			scopeErr.AnyOf = slices.Clone(e.AnyOf)
+		}
+	}
+
+	if scopeErr.TokenType == AnyToken && len(scopeErr.Missing) == 0 && len(scopeErr.AnyOf) == 0 {
+		return nil
+	}
+
+	return scopeErr
+}
@@ -2,6 +2,7 @@ package twitchhelix
 
 import (
 	"context"
+	"iter"
 
 	"github.com/google/go-querystring/query"
 )
@@ -82,6 +83,10 @@ type Channel struct {
 
 // SearchChannels searches for channels that match the specified query.
 // Results can be filtered to include only live channels.
+//
+// If the client has a Cache, results are served from it while fresh.
+//
+// Accepts a user or app access token.
 func (c *Client) SearchChannels(ctx context.Context, req RequestSearchChannels) (*ResponseSearchChannels, error) {
 	var resp ResponseSearchChannels
 
@@ -90,6 +95,11 @@ func (c *Client) SearchChannels(ctx context.Context, req RequestSearchChannels)
 		return nil, err
 	}
 
+	cache := c.Cache()
+	if cache != nil && cache.get(CacheSearchChannels, values.Encode(), &resp) {
+		return &resp, nil
+	}
+
 	endpoint := "search/channels?" + values.Encode()
 
 	err = c.doRequest(ctx, "GET", endpoint, nil, &resp)
@@ -97,5 +107,27 @@ func (c *Client) SearchChannels(ctx context.Context, req RequestSearchChannels)
 		return nil, err
 	}
 
+	if cache != nil {
+		cache.set(CacheSearchChannels, values.Encode(), resp)
+	}
+
 	return &resp, nil
 }
+
+// AllSearchChannels returns an iterator over every channel matching the
+// search query, starting at req.After.
+func (c *Client) AllSearchChannels(ctx context.Context, req RequestSearchChannels) iter.Seq2[Channel, error] {
+	cursor, _ := startCursor(req.After, nil)
+
+	return Paginate(ctx, cursor, func(ctx context.Context, cursor string) ([]Channel, string, error) {
+		req := req
+		req.After, _ = pageCursors(cursor, false)
+
+		resp, err := c.SearchChannels(ctx, req)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return resp.Data, resp.Pagination.cursor(), nil
+	})
+}
@@ -50,13 +50,9 @@ type DropReason struct {
 }
 
 // SendMessage sends a message to a specified twitch channel.
+//
+// Requires a user access token with the user:write:chat scope, or an app
+// access token if the sender granted user:bot.
 func (c *Client) SendMessage(ctx context.Context, req SendMessageRequest) (*SendMessageResponse, error) {
-	var resp SendMessageResponse
-
-	err := c.doRequest(ctx, "POST", "chat/messages", req, &resp)
-	if err != nil {
-		return nil, err
-	}
-
-	return &resp, nil
+	return doDataRequest[SendMessageResponse](ctx, c, "POST", "chat/messages", req)
 }
@@ -23,6 +23,8 @@ type RequestSendShoutout struct {
 // SendShoutout sends a shoutout to a user in a channel.
 // You must be at least a moderator to perform this action.
 // A "/shoutout" can only be sent every 2 minutes. You will receive an error if you query too soon.
+//
+// Requires a user access token with the moderator:manage:shoutouts scope.
 func (c *Client) SendShoutout(ctx context.Context, req RequestSendShoutout) error {
 	values, err := query.Values(req)
 	if err != nil {
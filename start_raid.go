@@ -33,6 +33,8 @@ type StartRaidData struct {
 }
 
 // StartRaid attempts to start a raid to another channel.
+//
+// Requires a user access token with the channel:manage:raids scope.
 func (c *Client) StartRaid(ctx context.Context, req RequestStartRaid) (*ResponseStartRaid, error) {
 	var resp ResponseStartRaid
 
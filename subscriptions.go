@@ -3,6 +3,7 @@ package twitchhelix
 import (
 	"context"
 	"fmt"
+	"iter"
 
 	"github.com/google/go-querystring/query"
 )
@@ -65,6 +66,8 @@ type SubscriptionData struct {
 }
 
 // CheckSubscription gets the subscription data for a user to a broadcaster.
+//
+// Requires a user access token with the user:read:subscriptions scope.
 func (c *Client) CheckSubscription(ctx context.Context, req CheckSubscriptionRequest) (*CheckSubscriptionResponse, error) {
 	var resp CheckSubscriptionResponse
 	// Example Request URL:
@@ -183,6 +186,8 @@ type SubscriptionDataV2 struct {
 // GetSubscriptions will return the information for subscribers to a broadcaster
 //
 // The returned slice can be empty if there are no subscribers.
+//
+// Requires a user access token with the channel:read:subscriptions scope.
 func (c *Client) GetSubscriptions(ctx context.Context, req GetSubscriptionsRequest) (*GetSubscriptionsResponse, error) {
 	var resp GetSubscriptionsResponse
 
@@ -201,3 +206,24 @@ func (c *Client) GetSubscriptions(ctx context.Context, req GetSubscriptionsReque
 
 	return &resp, nil
 }
+
+// AllSubscriptions returns an iterator over every subscription to the
+// broadcaster matching req.
+//
+// Paging starts at req.After, or walks backwards from req.Before if only
+// Before is set.
+func (c *Client) AllSubscriptions(ctx context.Context, req GetSubscriptionsRequest) iter.Seq2[SubscriptionDataV2, error] {
+	cursor, backward := startCursor(req.After, req.Before)
+
+	return Paginate(ctx, cursor, func(ctx context.Context, cursor string) ([]SubscriptionDataV2, string, error) {
+		req := req
+		req.After, req.Before = pageCursors(cursor, backward)
+
+		resp, err := c.GetSubscriptions(ctx, req)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return resp.Data, resp.Pagination.cursor(), nil
+	})
+}
@@ -0,0 +1,91 @@
+package twitchhelix
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// TokenSource supplies the token used to authorize requests.
+//
+// A Client consults its TokenSource before every request, so implementations
+// must be safe for concurrent use.
+type TokenSource interface {
+	// Token returns a token that can be used for the next request.
+	Token(ctx context.Context) (*NewToken, error)
+}
+
+// RenewableTokenSource is a TokenSource that can replace a token Twitch
+// rejected with 401 Unauthorized.
+type RenewableTokenSource interface {
+	TokenSource
+
+	// Renew returns a new token if rejected is still the current access
+	// token, or the current token if it was already replaced.
+	Renew(ctx context.Context, rejected string) (*NewToken, error)
+}
+
+// TokenStore persists the token a Client refreshes.
+//
+// Implementations must be safe for concurrent use.
+type TokenStore interface {
+	// Load returns the stored token.
+	//
+	// It returns nil and no error when no token has been stored yet.
+	Load() (*NewToken, error)
+
+	// Save replaces the stored token.
+	Save(token *NewToken) error
+}
+
+// MemoryTokenStore is a TokenStore that keeps the token in memory.
+//
+// The token is replaced atomically, so Load never observes a partially
+// saved token.
+type MemoryTokenStore struct {
+	// token is the stored token, nil if none. The pointed to token is
+	// never modified.
+	token atomic.Pointer[NewToken]
+}
+
+// NewMemoryTokenStore returns a MemoryTokenStore holding token.
+// token can be nil.
+func NewMemoryTokenStore(token *NewToken) *MemoryTokenStore {
+	s := &MemoryTokenStore{}
+	s.token.Store(token.clone())
+
+	return s
+}
+
+// Load returns a copy of the stored token.
+func (s *MemoryTokenStore) Load() (*NewToken, error) {
+	return s.token.Load().clone(), nil
+}
+
+// Save replaces the stored token with a copy of token.
+func (s *MemoryTokenStore) Save(token *NewToken) error {
+	s.token.Store(token.clone())
+
+	return nil
+}
+
+// clone returns a deep copy of t, nil if t is nil.
+func (t *NewToken) clone() *NewToken {
+	if t == nil {
+		return nil
+	}
+
+	clone := *t
+	clone.Scope = append([]string(nil), t.Scope...)
+
+	return &clone
+}
+
+// expiresWithin reports whether t is known to expire within d.
+func (t *NewToken) expiresWithin(d time.Duration) bool {
+	if t == nil || t.Expiry.IsZero() {
+		return false
+	}
+
+	return time.Until(t.Expiry) < d
+}
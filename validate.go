@@ -0,0 +1,188 @@
+package twitchhelix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultValidateInterval is how often RunTokenValidator validates the
+// token. Twitch requires applications to validate tokens hourly.
+const defaultValidateInterval = time.Hour
+
+// ValidateTokenResponse describes a token, as reported by the OAuth
+// validate endpoint.
+type ValidateTokenResponse struct {
+	// ClientID is the ID of the application the token was issued to.
+	ClientID string `json:"client_id"`
+
+	// Login is the login of the user the token belongs to.
+	//
+	// Empty for app access tokens.
+	Login string `json:"login"`
+
+	// UserID is the ID of the user the token belongs to.
+	//
+	// Empty for app access tokens.
+	UserID string `json:"user_id"`
+
+	// Scopes are the scopes granted to the token.
+	Scopes []string `json:"scopes"`
+
+	// ExpiresIn is the number of seconds until the token expires.
+	ExpiresIn int `json:"expires_in"`
+}
+
+// ValidateToken validates the client's current access token.
+//
+// The result is remembered and returned by TokenInfo and Scopes. An invalid
+// token is reported as an *OAuthError with status 401.
+func (c *Client) ValidateToken(ctx context.Context) (*ValidateTokenResponse, error) {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.authURL+"validate", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new http request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "OAuth "+token)
+	c.setUserAgent(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newOAuthError(resp.StatusCode, body)
+	}
+
+	var validation ValidateTokenResponse
+
+	err = json.Unmarshal(body, &validation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	c.mu.Lock()
+	c.validation = &validation
+	c.mu.Unlock()
+
+	return &validation, nil
+}
+
+// RevokeToken revokes token, which can be an access token or a refresh
+// token issued to the client's application.
+func (c *Client) RevokeToken(ctx context.Context, token string) error {
+	data := url.Values{}
+	data.Add("client_id", *c.clientID)
+	data.Add("token", token)
+
+	return c.postOAuth(ctx, "revoke", data, nil)
+}
+
+// TokenInfo returns the result of the last successful ValidateToken call,
+// nil if the token was not validated yet.
+func (c *Client) TokenInfo() *ValidateTokenResponse {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.validation == nil {
+		return nil
+	}
+
+	info := *c.validation
+	info.Scopes = slices.Clone(c.validation.Scopes)
+
+	return &info
+}
+
+// Scopes returns the scopes granted to the client's token.
+//
+// They are taken from the last ValidateToken call, or else from the stored
+// token. Scopes returns nil if neither is known.
+func (c *Client) Scopes() []string {
+	if info := c.TokenInfo(); info != nil {
+		return info.Scopes
+	}
+
+	c.mu.RLock()
+	store := c.tokenStore
+	c.mu.RUnlock()
+
+	token, err := store.Load()
+	if err != nil || token == nil {
+		return nil
+	}
+
+	return token.Scope
+}
+
+// HasScopes reports whether the client's token is known to have every one
+// of scopes.
+func (c *Client) HasScopes(scopes ...string) bool {
+	granted := c.Scopes()
+
+	for _, scope := range scopes {
+		if !slices.Contains(granted, scope) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// RunTokenValidator validates the client's token right away and then every
+// interval until ctx is done, as Twitch requires applications to do.
+// interval <= 0 validates hourly.
+//
+// When the token is found invalid, onInvalid is called with the error.
+// Other failures, such as network errors, are logged and retried at the
+// next interval. RunTokenValidator blocks; run it in its own goroutine.
+func (c *Client) RunTokenValidator(ctx context.Context, interval time.Duration, onInvalid func(error)) {
+	if interval <= 0 {
+		interval = defaultValidateInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := c.ValidateToken(ctx)
+
+		var oauthErr *OAuthError
+
+		switch {
+		case errors.As(err, &oauthErr) && oauthErr.StatusCode == http.StatusUnauthorized:
+			if onInvalid != nil {
+				onInvalid(err)
+			}
+		case err != nil && ctx.Err() == nil:
+			c.logger.Warn("failed to validate token", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}